@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/haikalvidya/go-article/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	jwtCmd = &cobra.Command{
+		Use:   "jwt",
+		Short: "Manage the jwt signing keys",
+	}
+	jwtRotateCmd = &cobra.Command{
+		Use:   "rotate",
+		Short: "Create a new jwt signing key and delete the keys that are no longer needed",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			err = app.Run(
+				app.TypeJWT,
+				app.WithArgs(args),
+				app.WithSubCmd("rotate"),
+			)
+
+			return
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(jwtCmd)
+	jwtCmd.AddCommand(jwtRotateCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/haikalvidya/go-article/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchCmd = &cobra.Command{
+		Use:   "search",
+		Short: "Manage the article search index",
+	}
+	searchReindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the search index from the published articles in the database",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			err = app.Run(
+				app.TypeSearch,
+				app.WithArgs(args),
+				app.WithSubCmd("reindex"),
+			)
+
+			return
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.AddCommand(searchReindexCmd)
+}
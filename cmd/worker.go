@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/haikalvidya/go-article/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run the background worker that publishes scheduled articles",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		err = app.Run(
+			app.TypeWorker,
+			app.WithArgs(args),
+		)
+
+		return
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+}
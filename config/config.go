@@ -7,21 +7,47 @@ const (
 	PRODUCTION = "production"
 )
 
+// the databases the app can run on, they match the names of the gorm dialectors
+const (
+	DRIVER_MYSQL    = "mysql"
+	DRIVER_POSTGRES = "postgres"
+	DRIVER_SQLITE   = "sqlite"
+)
+
 type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Redis    RedisConfig    `mapstructure:"redis"`
+	Worker   WorkerConfig   `mapstructure:"worker"`
+	Search   SearchConfig   `mapstructure:"search"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	OIDC     OIDCConfig     `mapstructure:"oidc"`
 }
 
 type ServerConfig struct {
+	// shown as the issuer in the authenticator apps
+	Name              string `mapstructure:"name"`
 	Address           string `mapstructure:"address"`
 	Env               string `mapstructure:"env"`
 	BaseURL           string `mapstructure:"base_url"`
 	InternalAccessKey string `mapstructure:"internal_access_key"`
+	// secret used to sign the pagination cursors
+	CursorSecret string `mapstructure:"cursor_secret"`
+	// page where the user picks a new password, the reset token is added as the token query param
+	PasswordResetURL string `mapstructure:"password_reset_url"`
+	// page that verifies the email, the verification token is added as the token query param
+	EmailVerificationURL    string `mapstructure:"email_verification_url"`
+	EmailVerificationSecret string `mapstructure:"email_verification_secret"`
+	// key the totp secrets of the users are encrypted with in the database
+	TotpEncryptionKey string `mapstructure:"totp_encryption_key"`
+	// users have to verify their email before they can create articles
+	RequireEmailVerification bool `mapstructure:"require_email_verification"`
 }
 
 type DatabaseConfig struct {
+	// mysql, postgres or sqlite, sqlite keeps the database in the file at name
+	Driver             string `mapstructure:"driver"`
 	Host               string `mapstructure:"host"`
 	Port               string `mapstructure:"port"`
 	User               string `mapstructure:"user"`
@@ -29,10 +55,15 @@ type DatabaseConfig struct {
 	Name               string `mapstructure:"name"`
 	Params             string `mapstructure:"params"`
 	MigrationTableName string `mapstructure:"migration_table_name"`
+	// a query is stopped when it runs longer than this, even when the request is still waiting
+	QueryTimeoutSecond int `mapstructure:"query_timeout_second"`
 }
 
 type JWTConfig struct {
+	// HS256 signs with the secret, RS256 and EdDSA sign with the newest key in key_dir
+	Algorithm              string `mapstructure:"algorithm"`
 	Secret                 string `mapstructure:"secret"`
+	KeyDir                 string `mapstructure:"key_dir"`
 	AccessTokenExpiredHour int    `mapstructure:"access_token_expire_hour"`
 	RefreshTokenExpireHour int    `mapstructure:"refresh_token_expire_hour"`
 }
@@ -42,6 +73,42 @@ type RedisConfig struct {
 	Port     string `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+	// how long a command waits for the server before it fails
+	TimeoutSecond int `mapstructure:"timeout_second"`
+}
+
+type WorkerConfig struct {
+	PollIntervalSecond int `mapstructure:"poll_interval_second"`
+	BatchSize          int `mapstructure:"batch_size"`
+}
+
+type SearchConfig struct {
+	// database uses the fulltext index of the database, the mysql fulltext index or
+	// the postgres tsvector index. sqlite has none so it matches with LIKE and
+	// lists the newest articles first. local keeps an inverted index in a file
+	Backend   string `mapstructure:"backend"`
+	IndexPath string `mapstructure:"index_path"`
+}
+
+type MailConfig struct {
+	// smtp sends the emails, log only writes them to files in dir
+	Driver   string `mapstructure:"driver"`
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Dir      string `mapstructure:"dir"`
+}
+
+type OIDCConfig struct {
+	// the login with the provider is off when the issuer is empty
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// page the provider sends the user back to, it posts the code and state to /oidc/callback
+	RedirectURL string   `mapstructure:"redirect_url"`
+	Scopes      []string `mapstructure:"scopes"`
 }
 
 func Load(cfgName string, paths ...string) (c *Config, err error) {
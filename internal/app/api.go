@@ -10,6 +10,7 @@ import (
 	"github.com/haikalvidya/go-article/internal/usecase"
 
 	"github.com/haikalvidya/go-article/pkg"
+	"github.com/haikalvidya/go-article/pkg/cache"
 
 	"github.com/haikalvidya/go-article/pkg/utils"
 
@@ -33,9 +34,26 @@ func (a *httpApp) Init() (err error) {
 	if err != nil {
 		return
 	}
-	a.repo = repository.NewRepository(a.db)
-	a.middleware = middlewares.New(a.config)
-	a.usecase = usecase.NewUsecase(a.repo, a.middleware, a.redis, &a.config.Server)
+	err = checkSecrets(a.config)
+	if err != nil {
+		return
+	}
+	searchIndex, err := InitSearch(a.config, a.db)
+	if err != nil {
+		return
+	}
+	mail, err := InitMailer(a.config)
+	if err != nil {
+		return
+	}
+	jwtKeys, err := InitJWTKeys(a.config)
+	if err != nil {
+		return
+	}
+
+	a.repo = repository.NewRepository(a.db, a.redis)
+	a.middleware = middlewares.New(a.config, a.repo, jwtKeys)
+	a.usecase = usecase.NewUsecase(a.repo, a.middleware, cache.NewRedisCache(a.redis), &a.config.Server, searchIndex, mail, InitOIDC(a.config))
 
 	e := echo.New()
 
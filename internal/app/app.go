@@ -1,13 +1,14 @@
 package app
 
 import (
+	"errors"
 	"log"
 
 	"github.com/haikalvidya/go-article/pkg/migration"
 
 	"github.com/haikalvidya/go-article/config"
 
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 )
 
@@ -45,6 +46,21 @@ func (a *base) initConfig() (err error) {
 	return
 }
 
+// the secrets that sign tokens the usecases trust, with an empty one anyone could
+// make those tokens. checked by the apps that run the usecases
+func checkSecrets(cfg *config.Config) error {
+	if cfg.Server.CursorSecret == "" {
+		return errors.New("server.cursor_secret must be set")
+	}
+	if cfg.Server.EmailVerificationSecret == "" {
+		return errors.New("server.email_verification_secret must be set")
+	}
+	if cfg.Server.TotpEncryptionKey == "" {
+		return errors.New("server.totp_encryption_key must be set")
+	}
+	return nil
+}
+
 func (a *base) closeConfig() {
 
 	if db, err := a.db.DB(); err == nil {
@@ -63,6 +79,9 @@ type Application interface {
 const (
 	TypeHTTPServer = "http"
 	TypeMigration  = "migration"
+	TypeWorker     = "worker"
+	TypeSearch     = "search"
+	TypeJWT        = "jwt"
 )
 
 func Run(appType string, opts ...FnOption) (err error) {
@@ -90,6 +109,18 @@ func New(typeApp string, fnOpts ...FnOption) Application {
 			base:    defaultBase,
 			Options: o.MigrationOptions,
 		}
+	case TypeWorker:
+		return &workerApp{
+			base: defaultBase,
+		}
+	case TypeSearch:
+		return &searchApp{
+			base: defaultBase,
+		}
+	case TypeJWT:
+		return &jwtApp{
+			base: defaultBase,
+		}
 	default:
 		return &httpApp{
 			base: defaultBase,
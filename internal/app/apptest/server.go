@@ -0,0 +1,164 @@
+// Package apptest runs the api on an httptest server with the repositories,
+// cache, search index and mailer kept in memory, so tests can call the api
+// without mysql and redis. NewSQLiteServer keeps the tables in sqlite instead
+// so the tests can check the sql repositories behave like the memory ones.
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/app"
+	"github.com/haikalvidya/go-article/internal/delivery"
+	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/internal/repository/memory"
+	"github.com/haikalvidya/go-article/internal/usecase"
+
+	"github.com/haikalvidya/go-article/pkg/cache"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+	"github.com/haikalvidya/go-article/pkg/search"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type Server struct {
+	// the base url of the api
+	URL    string
+	Config *config.Config
+	Repo   *repository.Repository
+	Cache  *cache.MemoryCache
+	Search *search.LocalIndex
+	Mailer *mailer.MemoryMailer
+
+	server *httptest.Server
+	// the sqlite database of NewSQLiteServer
+	db *gorm.DB
+}
+
+// the config the server starts with, the options can change it before the api is built
+func DefaultConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			Name:                    "go-article",
+			Env:                     config.DEV,
+			InternalAccessKey:       "internal-access-key",
+			CursorSecret:            "cursor-secret",
+			PasswordResetURL:        "http://localhost/password/reset",
+			EmailVerificationURL:    "http://localhost/email/verify",
+			EmailVerificationSecret: "email-verification-secret",
+			TotpEncryptionKey:       "totp-encryption-key",
+		},
+		JWT: config.JWTConfig{
+			Algorithm:              "HS256",
+			Secret:                 "jwt-secret",
+			AccessTokenExpiredHour: 1,
+			RefreshTokenExpireHour: 24,
+		},
+	}
+}
+
+func NewServer(options ...func(cfg *config.Config)) (*Server, error) {
+	cfg := DefaultConfig()
+	for _, option := range options {
+		option(cfg)
+	}
+	return newServer(cfg, memory.NewRepository())
+}
+
+func newServer(cfg *config.Config, repo *repository.Repository) (*Server, error) {
+	// an empty path keeps the index in memory only
+	searchIndex, err := search.NewLocalIndex("")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Config: cfg,
+		Repo:   repo,
+		Cache:  cache.NewMemoryCache(),
+		Search: searchIndex,
+		Mailer: mailer.NewMemoryMailer(),
+	}
+
+	// the keys are read from jwt.key_dir when the algorithm uses them
+	jwtKeys, err := app.InitJWTKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mid := middlewares.New(cfg, s.Repo, jwtKeys)
+	usc := usecase.NewUsecase(s.Repo, mid, s.Cache, &cfg.Server, s.Search, s.Mailer, app.InitOIDC(cfg))
+
+	e := echo.New()
+	e.Validator = &utils.CustomValidator{Validator: validator.New()}
+	e.IPExtractor = echo.ExtractIPDirect()
+	delivery.NewDelivery(e, usc, mid)
+
+	s.server = httptest.NewServer(e)
+	s.URL = s.server.URL
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+	if s.db != nil {
+		if db, err := s.db.DB(); err == nil {
+			db.Close()
+		}
+	}
+}
+
+// send the body as json with the token as bearer when it is not empty, the
+// response is decoded into res when it is not nil
+func (s *Server) Do(method string, path string, token string, body interface{}, res interface{}) (int, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	return s.DoWithHeader(method, path, header, body, res)
+}
+
+// like Do with the headers given as they are, for api keys and the internal api
+func (s *Server) DoWithHeader(method string, path string, header http.Header, body interface{}, res interface{}) (int, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.URL+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	resp, err := s.server.Client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if res != nil {
+		err = json.NewDecoder(resp.Body).Decode(res)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,73 @@
+package apptest
+
+import (
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/app"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/internal/repository/memory"
+	"github.com/haikalvidya/go-article/pkg/migration"
+
+	"gorm.io/gorm/logger"
+)
+
+// NewSQLiteServer runs the api with the tables in the sqlite database at path,
+// made with the migrations in migrationsDir. what the api keeps in redis stays
+// in memory. sqlite needs cgo, without it the migrations fail with an unknown driver
+func NewSQLiteServer(path string, migrationsDir string, options ...func(cfg *config.Config)) (*Server, error) {
+	cfg := DefaultConfig()
+	cfg.Database.Driver = config.DRIVER_SQLITE
+	cfg.Database.Name = path
+	for _, option := range options {
+		option(cfg)
+	}
+
+	dbmate, err := migration.New(
+		migration.WithURL("sqlite:"+path),
+		migration.WithMigrationsDir(migrationsDir),
+		migration.DisableAutoDumpSchema(),
+		migration.DisableWaitBefore(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	dbmate.Log = discard{}
+
+	err = dbmate.CreateAndMigrate()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := app.InitDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	sql := repository.NewRepository(db, nil)
+	repo := memory.NewRepository()
+	repo.User = sql.User
+	repo.Article = sql.Article
+	repo.ArticleRevision = sql.ArticleRevision
+	repo.Comment = sql.Comment
+	repo.RecoveryCode = sql.RecoveryCode
+	repo.APIKey = sql.APIKey
+	repo.Tag = sql.Tag
+	repo.Tx = sql.Tx
+
+	s, err := newServer(cfg, repo)
+	if err != nil {
+		if db, err := db.DB(); err == nil {
+			db.Close()
+		}
+		return nil, err
+	}
+	s.db = db
+	return s, nil
+}
+
+// the migrations print every step, the tests do not need that
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -1,19 +1,68 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"time"
 
 	"github.com/haikalvidya/go-article/config"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// the connections wait for each other instead of failing while the file is locked,
+// and a transaction takes the write lock when it begins so two transactions can
+// not deadlock on turning their read locks into write locks
+const defaultSQLiteParams = "_foreign_keys=1&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+
+// mysql when the driver is not set, for the configs written before the other databases
+func databaseDriver(cfg *config.Config) string {
+	if cfg.Database.Driver == "" {
+		return config.DRIVER_MYSQL
+	}
+	return cfg.Database.Driver
+}
+
+// the url of the postgres database, used by gorm and by the migrations
+func postgresURL(cfg *config.Config) *url.URL {
+	return &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Database.User, cfg.Database.Password),
+		Host:     net.JoinHostPort(cfg.Database.Host, cfg.Database.Port),
+		Path:     "/" + cfg.Database.Name,
+		RawQuery: cfg.Database.Params,
+	}
+}
+
+func openDatabase(cfg *config.Config) (gorm.Dialector, error) {
+	switch databaseDriver(cfg) {
+	case config.DRIVER_MYSQL:
+		dsn := fmt.Sprintf(`%s:%s@tcp(%s:%s)/%s?%s`,
+			cfg.Database.User, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name, cfg.Database.Params)
+		return mysql.Open(dsn), nil
+	case config.DRIVER_POSTGRES:
+		return postgres.Open(postgresURL(cfg).String()), nil
+	case config.DRIVER_SQLITE:
+		params := cfg.Database.Params
+		if params == "" {
+			params = defaultSQLiteParams
+		}
+		return sqlite.Open("file:" + cfg.Database.Name + "?" + params), nil
+	}
+	return nil, fmt.Errorf("unknown database driver %q", cfg.Database.Driver)
+}
+
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(`%s:%s@tcp(%s:%s)/%s?%s`,
-		cfg.Database.User, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name, cfg.Database.Params)
+	dialector, err := openDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	var logMode logger.LogLevel
 
@@ -27,7 +76,7 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		Logger: logger.Default.LogMode(logMode),
 	}
 
-	db, err := gorm.Open(mysql.Open(dsn), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -37,5 +86,70 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	dbConfig.SetMaxOpenConns(50)
 	dbConfig.SetConnMaxIdleTime(time.Minute * 3)
 
+	queryTimeout := time.Duration(cfg.Database.QueryTimeoutSecond) * time.Second
+	if queryTimeout <= 0 {
+		queryTimeout = defaultQueryTimeout
+	}
+
+	err = registerQueryTimeout(db, queryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return db, err
 }
+
+const (
+	defaultQueryTimeout = 10 * time.Second
+
+	queryTimeoutKey = "app:query_timeout"
+)
+
+type queryTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// every query gets a deadline on top of the context it was given, so a slow query
+// stops even when the request is still waiting for it. the context is put back
+// after the query because a statement can run more than one query, like a count
+// and then a find. rows are read after the row callbacks return so Row, Rows and
+// Scan only stop with the context of the caller
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	before := func(tx *gorm.DB) {
+		parent := tx.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(queryTimeoutKey, &queryTimeout{parent: parent, cancel: cancel})
+	}
+	after := func(tx *gorm.DB) {
+		if value, ok := tx.InstanceGet(queryTimeoutKey); ok {
+			t := value.(*queryTimeout)
+			t.cancel()
+			tx.Statement.Context = t.parent
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register("app:query_timeout", before),
+		callbacks.Create().After("*").Register("app:query_timeout_cancel", after),
+		callbacks.Query().Before("*").Register("app:query_timeout", before),
+		callbacks.Query().After("*").Register("app:query_timeout_cancel", after),
+		callbacks.Update().Before("*").Register("app:query_timeout", before),
+		callbacks.Update().After("*").Register("app:query_timeout_cancel", after),
+		callbacks.Delete().Before("*").Register("app:query_timeout", before),
+		callbacks.Delete().After("*").Register("app:query_timeout_cancel", after),
+		callbacks.Raw().Before("*").Register("app:query_timeout", before),
+		callbacks.Raw().After("*").Register("app:query_timeout_cancel", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
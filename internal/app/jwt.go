@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/pkg/middleware"
+)
+
+const defaultJwtKeyDir = "storage/jwt-keys"
+
+func jwtKeyDir(cfg *config.Config) string {
+	if cfg.JWT.KeyDir == "" {
+		return defaultJwtKeyDir
+	}
+	return cfg.JWT.KeyDir
+}
+
+// the key set is nil when the tokens are signed with the shared secret
+func InitJWTKeys(cfg *config.Config) (*middleware.JwtKeySet, error) {
+	switch cfg.JWT.Algorithm {
+	case "", middleware.JWT_ALG_HS256:
+		return nil, nil
+	case middleware.JWT_ALG_RS256, middleware.JWT_ALG_EDDSA:
+		return middleware.NewJwtKeySet(jwtKeyDir(cfg))
+	}
+	return nil, fmt.Errorf("unknown jwt algorithm %q", cfg.JWT.Algorithm)
+}
+
+// the jwt app only works on the key directory so it does not open the database
+type jwtApp struct {
+	base
+}
+
+func (a *jwtApp) Init() (err error) {
+	a.config, err = config.Load("config", ".", "./config")
+	return
+}
+
+func (a *jwtApp) Run() (err error) {
+	switch a.SubCmd {
+	case "rotate":
+		err = a.runRotate()
+	}
+	return
+}
+
+func (a *jwtApp) Close() (err error) {
+	return
+}
+
+// make a new signing key and delete the keys no unexpired token can be signed with anymore,
+// the servers keep signing with the old key until they reload the directory
+func (a *jwtApp) runRotate() (err error) {
+	algorithm := a.config.JWT.Algorithm
+	if algorithm == "" || algorithm == middleware.JWT_ALG_HS256 {
+		return fmt.Errorf("jwt algorithm %q does not use keys, set jwt.algorithm to %s or %s", algorithm, middleware.JWT_ALG_RS256, middleware.JWT_ALG_EDDSA)
+	}
+
+	dir := jwtKeyDir(a.config)
+	key, err := middleware.GenerateJwtKey(dir, algorithm)
+	if err != nil {
+		return
+	}
+	log.Printf("Created %s key %s.", key.Algorithm, key.ID)
+
+	retention := time.Hour*time.Duration(a.config.JWT.AccessTokenExpiredHour) + time.Minute
+	deleted, err := middleware.PruneJwtKeys(dir, retention)
+	for _, id := range deleted {
+		log.Printf("Deleted expired key %s.", id)
+	}
+	return
+}
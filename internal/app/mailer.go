@@ -0,0 +1,24 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+)
+
+const defaultMailDir = "storage/mail"
+
+func InitMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "", mailer.DRIVER_LOG:
+		dir := cfg.Mail.Dir
+		if dir == "" {
+			dir = defaultMailDir
+		}
+		return mailer.NewLogMailer(dir, cfg.Mail.From), nil
+	case mailer.DRIVER_SMTP:
+		return mailer.NewSMTPMailer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From), nil
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+}
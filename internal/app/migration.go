@@ -2,7 +2,9 @@ package app
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/haikalvidya/go-article/config"
 	"github.com/haikalvidya/go-article/pkg/migration"
 
 	"github.com/amacneil/dbmate/pkg/dbmate"
@@ -20,18 +22,15 @@ func (a *migrationApp) Init() (err error) {
 		return
 	}
 
-	urlString := fmt.Sprintf(
-		"%s://%s:%s@%s:%s/%s",
-		"mysql",
-		a.config.Database.User,
-		a.config.Database.Password,
-		a.config.Database.Host,
-		a.config.Database.Port,
-		a.config.Database.Name,
-	)
+	urlString, err := migrationURL(a.config)
+	if err != nil {
+		return
+	}
 
+	// every database has its own migrations, they use the syntax of the database
 	configMigrations := []migration.FnOpt{
 		migration.WithURL(urlString),
+		migration.WithMigrationsDir(filepath.Join(migration.DefaultMigrationsDir, databaseDriver(a.config))),
 		migration.WithMigrationsTableName(a.config.Database.MigrationTableName),
 	}
 
@@ -39,6 +38,26 @@ func (a *migrationApp) Init() (err error) {
 	return
 }
 
+func migrationURL(cfg *config.Config) (string, error) {
+	switch databaseDriver(cfg) {
+	case config.DRIVER_MYSQL:
+		return fmt.Sprintf(
+			"%s://%s:%s@%s:%s/%s",
+			"mysql",
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.Host,
+			cfg.Database.Port,
+			cfg.Database.Name,
+		), nil
+	case config.DRIVER_POSTGRES:
+		return postgresURL(cfg).String(), nil
+	case config.DRIVER_SQLITE:
+		return "sqlite:" + cfg.Database.Name, nil
+	}
+	return "", fmt.Errorf("unknown database driver %q", cfg.Database.Driver)
+}
+
 func (a *migrationApp) Run() (err error) {
 	switch a.SubCmd {
 	case "new":
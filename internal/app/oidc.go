@@ -0,0 +1,21 @@
+package app
+
+import (
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/pkg/oidc"
+)
+
+// the provider is nil when the login with openid connect is not configured
+func InitOIDC(cfg *config.Config) *oidc.Provider {
+	if cfg.OIDC.Issuer == "" {
+		return nil
+	}
+
+	return oidc.NewProvider(oidc.Config{
+		Issuer:       cfg.OIDC.Issuer,
+		ClientID:     cfg.OIDC.ClientID,
+		ClientSecret: cfg.OIDC.ClientSecret,
+		RedirectURL:  cfg.OIDC.RedirectURL,
+		Scopes:       cfg.OIDC.Scopes,
+	}, nil)
+}
@@ -1,21 +1,36 @@
 package app
 
 import (
+	"context"
+	"time"
+
 	"github.com/haikalvidya/go-article/config"
 
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
 )
 
+const defaultRedisTimeout = 3 * time.Second
+
 func InitRedis(cfg *config.Config) (*redis.Client, error) {
+	timeout := time.Duration(cfg.Redis.TimeoutSecond) * time.Second
+	if timeout <= 0 {
+		timeout = defaultRedisTimeout
+	}
+
+	// a command stops when the context of the request is done, the read and
+	// write timeouts bound the commands run without a deadline
 	redisConfig := &redis.Options{
-		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
+		Addr:         cfg.Redis.Host + ":" + cfg.Redis.Port,
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
 	}
 
 	client := redis.NewClient(redisConfig)
 
-	_, err := client.Ping().Result()
+	_, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		return nil, err
 	}
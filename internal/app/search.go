@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/internal/usecase"
+	"github.com/haikalvidya/go-article/pkg/cache"
+	"github.com/haikalvidya/go-article/pkg/search"
+
+	"gorm.io/gorm"
+)
+
+const defaultSearchIndexPath = "storage/search.index"
+
+func InitSearch(cfg *config.Config, db *gorm.DB) (search.SearchIndex, error) {
+	switch cfg.Search.Backend {
+	case "", search.BACKEND_DATABASE:
+		return repository.NewArticleSearchIndex(db), nil
+	case search.BACKEND_LOCAL:
+		path := cfg.Search.IndexPath
+		if path == "" {
+			path = defaultSearchIndexPath
+		}
+		return search.NewLocalIndex(path)
+	}
+	return nil, fmt.Errorf("unknown search backend %q", cfg.Search.Backend)
+}
+
+type searchApp struct {
+	base
+	usecase *usecase.Usecase
+}
+
+func (a *searchApp) Init() (err error) {
+	err = a.initConfig()
+	if err != nil {
+		return
+	}
+	err = checkSecrets(a.config)
+	if err != nil {
+		return
+	}
+
+	searchIndex, err := InitSearch(a.config, a.db)
+	if err != nil {
+		return
+	}
+	mail, err := InitMailer(a.config)
+	if err != nil {
+		return
+	}
+	jwtKeys, err := InitJWTKeys(a.config)
+	if err != nil {
+		return
+	}
+
+	repo := repository.NewRepository(a.db, a.redis)
+	a.usecase = usecase.NewUsecase(repo, middlewares.New(a.config, repo, jwtKeys), cache.NewRedisCache(a.redis), &a.config.Server, searchIndex, mail, InitOIDC(a.config))
+	return
+}
+
+func (a *searchApp) Run() (err error) {
+	switch a.SubCmd {
+	case "reindex":
+		err = a.runReindex()
+	}
+	return
+}
+
+func (a *searchApp) Close() (err error) {
+	a.closeConfig()
+	return
+}
+
+func (a *searchApp) runReindex() (err error) {
+	indexed, err := a.usecase.Article.ReindexSearch(context.Background())
+	if err != nil {
+		return
+	}
+
+	log.Printf("Indexed %d articles.", indexed)
+	return
+}
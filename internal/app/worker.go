@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/internal/usecase"
+
+	"github.com/haikalvidya/go-article/pkg"
+	"github.com/haikalvidya/go-article/pkg/cache"
+)
+
+const (
+	defaultWorkerPollInterval = 30 * time.Second
+	defaultWorkerBatchSize    = 100
+)
+
+type workerApp struct {
+	base
+	usecase      *usecase.Usecase
+	repo         *repository.Repository
+	middleware   *middlewares.CustomMiddleware
+	signalWorker *pkg.GracefullShutdown
+	pollInterval time.Duration
+	batchSize    int
+}
+
+func (a *workerApp) Init() (err error) {
+	err = a.initConfig()
+	if err != nil {
+		return
+	}
+	err = checkSecrets(a.config)
+	if err != nil {
+		return
+	}
+	searchIndex, err := InitSearch(a.config, a.db)
+	if err != nil {
+		return
+	}
+	mail, err := InitMailer(a.config)
+	if err != nil {
+		return
+	}
+	jwtKeys, err := InitJWTKeys(a.config)
+	if err != nil {
+		return
+	}
+
+	a.repo = repository.NewRepository(a.db, a.redis)
+	a.middleware = middlewares.New(a.config, a.repo, jwtKeys)
+	a.usecase = usecase.NewUsecase(a.repo, a.middleware, cache.NewRedisCache(a.redis), &a.config.Server, searchIndex, mail, InitOIDC(a.config))
+
+	a.pollInterval = time.Duration(a.config.Worker.PollIntervalSecond) * time.Second
+	if a.pollInterval <= 0 {
+		a.pollInterval = defaultWorkerPollInterval
+	}
+
+	a.batchSize = a.config.Worker.BatchSize
+	if a.batchSize <= 0 {
+		a.batchSize = defaultWorkerBatchSize
+	}
+
+	a.signalWorker = pkg.NewGracefullShutdown()
+	return
+}
+
+func (a *workerApp) Run() (err error) {
+	// the context is cancelled on shutdown so a running batch stops its queries
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		a.signalWorker.Wait()
+
+		log.Println("Shutting down the worker!")
+		cancel()
+	}()
+
+	log.Println("Press Ctrl + C to exit the worker!")
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		a.publishScheduledArticles(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *workerApp) Close() (err error) {
+	a.closeConfig()
+	return
+}
+
+// publish scheduled articles batch by batch until nothing is due
+func (a *workerApp) publishScheduledArticles(ctx context.Context) {
+	for {
+		published, err := a.usecase.Article.PublishScheduledArticles(ctx, a.batchSize)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Error publishing scheduled articles: %v.", err)
+			return
+		}
+
+		if published > 0 {
+			log.Printf("Published %d scheduled articles.", published)
+		}
+
+		if published < a.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (d *userDelivery) CreateAPIKey(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.CreateAPIKeyRequest{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	keyRes, err := d.Usecase.User.CreateAPIKey(c.Request().Context(), userId, req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Create API Key"
+	res.Data = keyRes
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) GetAPIKeys(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	keys, err := d.Usecase.User.GetAPIKeys(c.Request().Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get API Keys"
+	res.Data = keys
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) RevokeAPIKey(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.RevokeAPIKey(c.Request().Context(), userId, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Revoke API Key"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,83 @@
+package delivery_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func callWithAPIKey(t *testing.T, server *apptest.Server, method string, path string, key string, body interface{}, status int, data interface{}) *response {
+	t.Helper()
+
+	header := http.Header{}
+	header.Set("Authorization", "ApiKey "+key)
+	return callWithHeader(t, server, method, path, header, body, status, data)
+}
+
+func createAPIKey(t *testing.T, server *apptest.Server, token string, scopes ...string) *payload.CreateAPIKeyResponse {
+	t.Helper()
+
+	key := &payload.CreateAPIKeyResponse{}
+	call(t, server, http.MethodPost, "/user/api-keys", token, &payload.CreateAPIKeyRequest{Name: "script", Scopes: scopes}, http.StatusOK, key)
+	return key
+}
+
+func TestAPIKey(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	key := createAPIKey(t, server, user.Token, "read", "write:articles")
+	info := &payload.UserInfo{}
+	callWithAPIKey(t, server, http.MethodGet, "/user", key.Key, nil, http.StatusOK, info)
+	if info.ID != user.UserInfo.ID {
+		t.Errorf("api key is for user %q, want %q", info.ID, user.UserInfo.ID)
+	}
+
+	article := &payload.ArticleInfo{}
+	callWithAPIKey(t, server, http.MethodPost, "/article", key.Key, &payload.CreateArticleRequest{
+		Title:   "From a script",
+		Content: "content",
+		Status:  "published",
+	}, http.StatusOK, article)
+	if article.Title != "From a script" {
+		t.Errorf("title = %q, want %q", article.Title, "From a script")
+	}
+
+	// the key itself is only shown once
+	keys := []*payload.APIKeyInfo{}
+	call(t, server, http.MethodGet, "/user/api-keys", user.Token, nil, http.StatusOK, &keys)
+	if len(keys) != 1 || keys[0].ID != key.ID {
+		t.Fatalf("keys = %+v, want only %q", keys, key.ID)
+	}
+}
+
+func TestAPIKeyRejections(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	key := createAPIKey(t, server, user.Token, "read")
+
+	res := callWithAPIKey(t, server, http.MethodPost, "/article", key.Key, &payload.CreateArticleRequest{
+		Title:   "From a script",
+		Content: "content",
+		Status:  "published",
+	}, http.StatusForbidden, nil)
+	if res.Code != payload.ErrAPIKeyScope.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrAPIKeyScope.Code)
+	}
+
+	// api keys can not manage the api keys
+	callWithAPIKey(t, server, http.MethodGet, "/user/api-keys", key.Key, nil, http.StatusBadRequest, nil)
+
+	res = callWithAPIKey(t, server, http.MethodGet, "/user", "forged-key", nil, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrAPIKeyInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrAPIKeyInvalid.Code)
+	}
+
+	call(t, server, http.MethodDelete, "/user/api-keys/"+key.ID, user.Token, nil, http.StatusOK, nil)
+	res = callWithAPIKey(t, server, http.MethodGet, "/user", key.Key, nil, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrAPIKeyInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrAPIKeyInvalid.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package delivery
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -21,17 +22,12 @@ func (d *articleDelivery) CreateArticle(c echo.Context) error {
 	c.Bind(req)
 
 	if err := c.Validate(req); err != nil {
-		res.Error = utils.GetErrorValidation(err)
-		res.Status = false
-		res.Message = "Failed Create Article"
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
-	articleRes, err := d.Usecase.Article.CreateArticle(userId, req)
+	articleRes, err := d.Usecase.Article.CreateArticle(c.Request().Context(), userId, req)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Create Article"
@@ -50,64 +46,51 @@ func (d *articleDelivery) GetAllArticle(c echo.Context) error {
 
 	// bind query param
 	if err := c.Bind(queryParam); err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
+	}
+
+	if err := c.Validate(queryParam); err != nil {
+		return err
 	}
 
 	articleRes := []*payload.ArticleInfo{}
+	var meta *common.Pagination
+	pagination := utils.GetPagination(c)
 
 	if queryParam.AuthorName != "" && queryParam.QuerySearch != "" {
-		userRes, err := d.Usecase.User.GetUserByName(queryParam.AuthorName)
+		userRes, err := d.Usecase.User.GetUserByName(c.Request().Context(), queryParam.AuthorName)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
-		articleRes, err = d.Usecase.Article.GetArticleSearchAndByAuthorID(userRes.ID, queryParam.QuerySearch)
+		articleRes, meta, err = d.Usecase.Article.GetArticleSearchAndByAuthorID(c.Request().Context(), userRes.ID, queryParam.QuerySearch, queryParam, pagination)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
 	} else if queryParam.QuerySearch != "" {
-		articleRes, err = d.Usecase.Article.SearchArticlesByTitleAndContent(queryParam.QuerySearch)
+		articleRes, meta, err = d.Usecase.Article.SearchArticlesByTitleAndContent(c.Request().Context(), queryParam.QuerySearch, queryParam, pagination)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
 	} else if queryParam.AuthorName != "" {
 		// get user id by author name
-		userRes, err := d.Usecase.User.GetUserByName(queryParam.AuthorName)
+		userRes, err := d.Usecase.User.GetUserByName(c.Request().Context(), queryParam.AuthorName)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
 
-		articleRes, err = d.Usecase.Article.GetArticlesByAuthorID(userRes.ID)
+		articleRes, meta, err = d.Usecase.Article.GetArticlesByAuthorID(c.Request().Context(), userRes.ID, queryParam, pagination)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
 	} else {
-		articleRes, err = d.Usecase.Article.GetAllArticles()
+		articleRes, meta, err = d.Usecase.Article.GetAllArticles(c.Request().Context(), queryParam, pagination)
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
-			res.Data = []*payload.ArticleInfo{}
-			return c.JSON(http.StatusBadRequest, res)
+			return err
 		}
 	}
 	res.Message = "Success Get All Article"
 	res.Data = articleRes
+	res.Meta = meta
 	res.Status = true
 
 	return c.JSON(http.StatusOK, res)
@@ -121,11 +104,9 @@ func (d *articleDelivery) GetArticleByID(c echo.Context) error {
 	// convert string to int
 	articleID, _ := strconv.Atoi(articleIDStr)
 
-	articleRes, err := d.Usecase.Article.GetArticleByID(articleID)
+	articleRes, err := d.Usecase.Article.GetArticleByID(c.Request().Context(), articleID)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Get Article By ID"
@@ -149,17 +130,12 @@ func (d *articleDelivery) UpdateArticle(c echo.Context) error {
 	c.Bind(req)
 
 	if err := c.Validate(req); err != nil {
-		res.Error = utils.GetErrorValidation(err)
-		res.Status = false
-		res.Message = "Failed Update Article"
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
-	articleRes, err := d.Usecase.Article.UpdateArticleByID(articleID, req, userId)
+	articleRes, err := d.Usecase.Article.UpdateArticleByID(c.Request().Context(), articleID, req, userId)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Update Article"
@@ -179,11 +155,9 @@ func (d *articleDelivery) DeleteArticle(c echo.Context) error {
 
 	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
 
-	err := d.Usecase.Article.DeleteArticleByID(articleID, userId)
+	err := d.Usecase.Article.DeleteArticleByID(c.Request().Context(), articleID, userId)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Delete Article"
@@ -191,3 +165,111 @@ func (d *articleDelivery) DeleteArticle(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, res)
 }
+
+// get articles of the logged in author, including drafts
+func (d *articleDelivery) GetMyArticles(c echo.Context) error {
+	res := common.Response{}
+	queryParam := &payload.MyArticleQuery{}
+
+	if err := c.Bind(queryParam); err != nil {
+		return err
+	}
+
+	if err := c.Validate(queryParam); err != nil {
+		return err
+	}
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	articleRes, meta, err := d.Usecase.Article.GetMyArticles(c.Request().Context(), userId, queryParam, utils.GetPagination(c))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get My Article"
+	res.Data = articleRes
+	res.Meta = meta
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// get article of the logged in author by id in any status
+func (d *articleDelivery) GetMyArticleByID(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	articleRes, err := d.Usecase.Article.GetMyArticleByID(c.Request().Context(), articleID, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get My Article By ID"
+	res.Data = articleRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// publish article
+func (d *articleDelivery) PublishArticle(c echo.Context) error {
+	return d.changeArticleStatus(c, d.Usecase.Article.PublishArticle, "Publish Article")
+}
+
+// unpublish article, it goes back to draft
+func (d *articleDelivery) UnpublishArticle(c echo.Context) error {
+	return d.changeArticleStatus(c, d.Usecase.Article.UnpublishArticle, "Unpublish Article")
+}
+
+// archive article
+func (d *articleDelivery) ArchiveArticle(c echo.Context) error {
+	return d.changeArticleStatus(c, d.Usecase.Article.ArchiveArticle, "Archive Article")
+}
+
+func (d *articleDelivery) changeArticleStatus(c echo.Context, change func(ctx context.Context, id int, authorId string) (*payload.ArticleInfo, error), action string) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	articleRes, err := change(c.Request().Context(), articleID, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success " + action
+	res.Data = articleRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// schedule article to be published later by the worker
+func (d *articleDelivery) ScheduleArticle(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.ScheduleArticleRequest{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	articleRes, err := d.Usecase.Article.ScheduleArticle(c.Request().Context(), articleID, req, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Schedule Article"
+	res.Data = articleRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,100 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+type articleRevisionDelivery deliveryType
+
+// get all revisions of article
+func (d *articleRevisionDelivery) GetRevisions(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	revisionRes, err := d.Usecase.ArticleRevision.GetRevisions(c.Request().Context(), articleID, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Article Revisions"
+	res.Data = revisionRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// get one revision of article
+func (d *articleRevisionDelivery) GetRevision(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	revision, _ := strconv.Atoi(c.Param("rev"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	revisionRes, err := d.Usecase.ArticleRevision.GetRevision(c.Request().Context(), articleID, revision, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Article Revision"
+	res.Data = revisionRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// line diff between two revisions of article
+func (d *articleRevisionDelivery) DiffRevisions(c echo.Context) error {
+	res := common.Response{}
+	queryParam := &payload.ArticleRevisionDiffQuery{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	if err := c.Bind(queryParam); err != nil {
+		return err
+	}
+
+	if err := c.Validate(queryParam); err != nil {
+		return err
+	}
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	diffRes, err := d.Usecase.ArticleRevision.DiffRevisions(c.Request().Context(), articleID, queryParam.From, queryParam.To, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Diff Article Revisions"
+	res.Data = diffRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// restore article to the content of a revision
+func (d *articleRevisionDelivery) RestoreRevision(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	revision, _ := strconv.Atoi(c.Param("rev"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	articleRes, err := d.Usecase.ArticleRevision.RestoreRevision(c.Request().Context(), articleID, revision, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Restore Article Revision"
+	res.Data = articleRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,209 @@
+package delivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func createArticle(t *testing.T, server *apptest.Server, token string, title string, tags ...string) *payload.ArticleInfo {
+	t.Helper()
+
+	article := &payload.ArticleInfo{}
+	call(t, server, http.MethodPost, "/article", token, &payload.CreateArticleRequest{
+		Title:   title,
+		Content: "content of " + title,
+		Status:  "published",
+		Tags:    tags,
+	}, http.StatusOK, article)
+	return article
+}
+
+// the ids of the published articles matching the query
+func listArticleIDs(t *testing.T, server *apptest.Server, query url.Values) []int {
+	t.Helper()
+
+	articles := []*payload.ArticleInfo{}
+	call(t, server, http.MethodGet, "/article?"+query.Encode(), "", nil, http.StatusOK, &articles)
+
+	ids := []int{}
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestArticleCRUD(t *testing.T) {
+	runOnBackends(t, testArticleCRUD)
+}
+
+func testArticleCRUD(t *testing.T, server *apptest.Server) {
+	author := register(t, server, "Author", "author@example.com")
+	other := register(t, server, "Other", "other@example.com")
+
+	created := createArticle(t, server, author.Token, "Testing in Go", " Go ", "testing", "go")
+	if !reflect.DeepEqual(created.Tags, []string{"go", "testing"}) {
+		t.Errorf("tags = %v, want the normalized [go testing]", created.Tags)
+	}
+
+	got := &payload.ArticleInfo{}
+	call(t, server, http.MethodGet, fmt.Sprintf("/article/%d", created.ID), "", nil, http.StatusOK, got)
+	if got.Title != "Testing in Go" || got.AuthorID != author.UserInfo.ID || got.Status != "published" {
+		t.Errorf("article = %+v", got)
+	}
+
+	// only the author can change the article
+	res := call(t, server, http.MethodPut, fmt.Sprintf("/article/%d", created.ID), other.Token, &payload.UpdateArticleRequest{Title: "Stolen"}, http.StatusForbidden, nil)
+	if res.Code != payload.ErrArticleNotAllowed.Code {
+		t.Errorf("update by another user code = %q, want %q", res.Code, payload.ErrArticleNotAllowed.Code)
+	}
+
+	tags := []string{"go", "tooling"}
+	updated := &payload.ArticleInfo{}
+	call(t, server, http.MethodPut, fmt.Sprintf("/article/%d", created.ID), author.Token, &payload.UpdateArticleRequest{Title: "Tooling in Go", Tags: &tags}, http.StatusOK, updated)
+	if updated.Title != "Tooling in Go" || !reflect.DeepEqual(updated.Tags, tags) {
+		t.Errorf("updated = %+v, want the new title and tags", updated)
+	}
+
+	// the cached article was replaced
+	call(t, server, http.MethodGet, fmt.Sprintf("/article/%d", created.ID), "", nil, http.StatusOK, got)
+	if got.Title != "Tooling in Go" {
+		t.Errorf("article after the update = %q, want the new title", got.Title)
+	}
+
+	call(t, server, http.MethodDelete, fmt.Sprintf("/article/%d", created.ID), other.Token, nil, http.StatusForbidden, nil)
+	call(t, server, http.MethodDelete, fmt.Sprintf("/article/%d", created.ID), author.Token, nil, http.StatusOK, nil)
+
+	res = call(t, server, http.MethodGet, fmt.Sprintf("/article/%d", created.ID), "", nil, http.StatusNotFound, nil)
+	if res.Code != payload.ErrArticleNotFound.Code {
+		t.Errorf("deleted article code = %q, want %q", res.Code, payload.ErrArticleNotFound.Code)
+	}
+	if ids := listArticleIDs(t, server, url.Values{}); len(ids) != 0 {
+		t.Errorf("deleted article is listed: %v", ids)
+	}
+}
+
+func TestArticleTagFilters(t *testing.T) {
+	runOnBackends(t, testArticleTagFilters)
+}
+
+func testArticleTagFilters(t *testing.T, server *apptest.Server) {
+	author := register(t, server, "Author", "author@example.com")
+
+	both := createArticle(t, server, author.Token, "Both", "go", "testing")
+	goOnly := createArticle(t, server, author.Token, "Go only", "go")
+	testingOnly := createArticle(t, server, author.Token, "Testing only", "testing")
+	createArticle(t, server, author.Token, "No tags")
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []int
+	}{
+		{name: "one tag", query: url.Values{"tag": {"go"}}, want: []int{both.ID, goOnly.ID}},
+		{name: "tags are normalized", query: url.Values{"tag": {"GO "}}, want: []int{both.ID, goOnly.ID}},
+		{name: "every tag by default", query: url.Values{"tag": {"go", "testing"}}, want: []int{both.ID}},
+		{name: "any tag", query: url.Values{"tag": {"go", "testing"}, "tag_mode": {"or"}}, want: []int{both.ID, goOnly.ID, testingOnly.ID}},
+		{name: "unknown tag", query: url.Values{"tag": {"rust"}}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listArticleIDs(t, server, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GET /article?%s = %v, want %v", tt.query.Encode(), got, tt.want)
+			}
+		})
+	}
+
+	tags := []*payload.TagInfo{}
+	call(t, server, http.MethodGet, "/tags", "", nil, http.StatusOK, &tags)
+	counts := map[string]int64{}
+	for _, tag := range tags {
+		counts[tag.Name] = tag.ArticleCount
+	}
+	if want := map[string]int64{"go": 2, "testing": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("tags = %v, want %v", counts, want)
+	}
+}
+
+// follow the next cursors from the first page and return the ids in the order they came
+func walkCursor(t *testing.T, server *apptest.Server, perPage int) ([]int, []string) {
+	t.Helper()
+
+	ids := []int{}
+	cursors := []string{""}
+	for {
+		articles := []*payload.ArticleInfo{}
+		query := url.Values{"cursor": {cursors[len(cursors)-1]}, "perpage": {fmt.Sprint(perPage)}}
+		res := call(t, server, http.MethodGet, "/article?"+query.Encode(), "", nil, http.StatusOK, &articles)
+		for _, article := range articles {
+			ids = append(ids, article.ID)
+		}
+
+		if res.Meta == nil || res.Meta.NextCursor == "" {
+			return ids, cursors
+		}
+		if len(cursors) > 10 {
+			t.Fatalf("the cursor does not end, ids %v", ids)
+		}
+		cursors = append(cursors, res.Meta.NextCursor)
+	}
+}
+
+func TestArticleCursorPagination(t *testing.T) {
+	runOnBackends(t, testArticleCursorPagination)
+}
+
+func testArticleCursorPagination(t *testing.T, server *apptest.Server) {
+	author := register(t, server, "Author", "author@example.com")
+
+	want := []int{}
+	for i := 0; i < 7; i++ {
+		article := createArticle(t, server, author.Token, fmt.Sprintf("Article %d", i))
+		want = append([]int{article.ID}, want...)
+	}
+
+	// newest first, every article once, even when they were made in the same second
+	ids, cursors := walkCursor(t, server, 3)
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("cursor pages = %v, want %v", ids, want)
+	}
+	if len(cursors) != 3 {
+		t.Errorf("got %d pages, want 3", len(cursors))
+	}
+
+	// the previous cursor of the second page leads back to the first page
+	res := call(t, server, http.MethodGet, "/article?"+url.Values{"cursor": {cursors[1]}, "perpage": {"3"}}.Encode(), "", nil, http.StatusOK, nil)
+	if res.Meta.PrevCursor == "" {
+		t.Fatal("the second page has no previous cursor")
+	}
+	first := []*payload.ArticleInfo{}
+	call(t, server, http.MethodGet, "/article?"+url.Values{"cursor": {res.Meta.PrevCursor}, "perpage": {"3"}}.Encode(), "", nil, http.StatusOK, &first)
+	if len(first) != 3 || first[0].ID != want[0] || first[2].ID != want[2] {
+		t.Errorf("previous page = %d articles starting with %d, want %v", len(first), first[0].ID, want[:3])
+	}
+
+	// an article made while paging does not shift the next pages
+	createArticle(t, server, author.Token, "Newest")
+	next := []*payload.ArticleInfo{}
+	call(t, server, http.MethodGet, "/article?"+url.Values{"cursor": {cursors[1]}, "perpage": {"3"}}.Encode(), "", nil, http.StatusOK, &next)
+	if len(next) != 3 || next[0].ID != want[3] {
+		t.Errorf("second page after a new article starts with %v, want %d", next, want[3])
+	}
+
+	res = call(t, server, http.MethodGet, "/article?cursor=forged", "", nil, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrCursorInvalid.Code {
+		t.Errorf("forged cursor code = %q, want %q", res.Code, payload.ErrCursorInvalid.Code)
+	}
+	res = call(t, server, http.MethodGet, "/article?cursor=&sort=title", "", nil, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrCursorSort.Code {
+		t.Errorf("cursor sorted by title code = %q, want %q", res.Code, payload.ErrCursorSort.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+type commentDelivery deliveryType
+
+// get comments of article as a thread
+func (d *commentDelivery) GetComments(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	commentRes, err := d.Usecase.Comment.GetComments(c.Request().Context(), articleID)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Comments"
+	res.Data = commentRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// create comment or reply on article
+func (d *commentDelivery) CreateComment(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.CreateCommentRequest{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	commentRes, err := d.Usecase.Comment.CreateComment(c.Request().Context(), articleID, userId, req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Create Comment"
+	res.Data = commentRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// update comment by its author
+func (d *commentDelivery) UpdateComment(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.UpdateCommentRequest{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	commentRes, err := d.Usecase.Comment.UpdateComment(c.Request().Context(), articleID, commentID, userId, req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Update Comment"
+	res.Data = commentRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// delete comment by its author
+func (d *commentDelivery) DeleteComment(c echo.Context) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.Comment.DeleteComment(c.Request().Context(), articleID, commentID, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Delete Comment"
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// hide comment by the article author
+func (d *commentDelivery) HideComment(c echo.Context) error {
+	return d.moderateComment(c, d.Usecase.Comment.HideComment, "Hide Comment")
+}
+
+// unhide comment by the article author
+func (d *commentDelivery) UnhideComment(c echo.Context) error {
+	return d.moderateComment(c, d.Usecase.Comment.UnhideComment, "Unhide Comment")
+}
+
+func (d *commentDelivery) moderateComment(c echo.Context, moderate func(ctx context.Context, articleID int, commentID int, articleAuthorID string) (*payload.CommentInfo, error), action string) error {
+	res := common.Response{}
+	articleID, _ := strconv.Atoi(c.Param("id"))
+	commentID, _ := strconv.Atoi(c.Param("comment_id"))
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	commentRes, err := moderate(c.Request().Context(), articleID, commentID, userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success " + action
+	res.Data = commentRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
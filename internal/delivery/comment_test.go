@@ -0,0 +1,85 @@
+package delivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func TestComments(t *testing.T) {
+	runOnBackends(t, testComments)
+}
+
+func testComments(t *testing.T, server *apptest.Server) {
+	author := register(t, server, "Author", "author@example.com")
+	reader := register(t, server, "Reader", "reader@example.com")
+	article := createArticle(t, server, author.Token, "Commented")
+	path := fmt.Sprintf("/article/%d/comments", article.ID)
+
+	comment := &payload.CommentInfo{}
+	call(t, server, http.MethodPost, path, reader.Token, &payload.CreateCommentRequest{Content: "first"}, http.StatusOK, comment)
+	reply := &payload.CommentInfo{}
+	call(t, server, http.MethodPost, path, author.Token, &payload.CreateCommentRequest{Content: "reply", ParentID: &comment.ID}, http.StatusOK, reply)
+
+	missing := 12345
+	res := call(t, server, http.MethodPost, path, reader.Token, &payload.CreateCommentRequest{Content: "orphan", ParentID: &missing}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrCommentParent.Code {
+		t.Errorf("unknown parent code = %q, want %q", res.Code, payload.ErrCommentParent.Code)
+	}
+	// echo answers a missing token with a bad request
+	call(t, server, http.MethodPost, path, "", &payload.CreateCommentRequest{Content: "anonymous"}, http.StatusBadRequest, nil)
+
+	thread := []*payload.CommentInfo{}
+	call(t, server, http.MethodGet, path, "", nil, http.StatusOK, &thread)
+	if len(thread) != 1 || thread[0].Content != "first" || len(thread[0].Replies) != 1 || thread[0].Replies[0].Content != "reply" {
+		t.Fatalf("thread = %+v, want the comment with its reply", thread)
+	}
+
+	got := &payload.ArticleInfo{}
+	call(t, server, http.MethodGet, fmt.Sprintf("/article/%d", article.ID), "", nil, http.StatusOK, got)
+	if got.CommentCount != 2 {
+		t.Errorf("comment count = %d, want 2", got.CommentCount)
+	}
+
+	// only the writer can edit the comment
+	commentPath := fmt.Sprintf("%s/%d", path, comment.ID)
+	res = call(t, server, http.MethodPut, commentPath, author.Token, &payload.UpdateCommentRequest{Content: "changed"}, http.StatusForbidden, nil)
+	if res.Code != payload.ErrCommentNotAllowed.Code {
+		t.Errorf("edit by another user code = %q, want %q", res.Code, payload.ErrCommentNotAllowed.Code)
+	}
+	updated := &payload.CommentInfo{}
+	call(t, server, http.MethodPut, commentPath, reader.Token, &payload.UpdateCommentRequest{Content: "edited"}, http.StatusOK, updated)
+	if updated.Content != "edited" || updated.UpdatedAt == "" {
+		t.Errorf("updated = %+v, want the new content and an update time", updated)
+	}
+
+	// the author of the article hides the comment, the reader can not
+	call(t, server, http.MethodPost, commentPath+"/hide", reader.Token, nil, http.StatusForbidden, nil)
+	call(t, server, http.MethodPost, commentPath+"/hide", author.Token, nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, path, "", nil, http.StatusOK, &thread)
+	if !thread[0].Hidden || thread[0].Content != "" || len(thread[0].Replies) != 1 {
+		t.Errorf("hidden comment = %+v, want it without content but with its reply", thread[0])
+	}
+	call(t, server, http.MethodPost, commentPath+"/unhide", author.Token, nil, http.StatusOK, nil)
+
+	// a deleted comment keeps its place while it has replies
+	call(t, server, http.MethodDelete, commentPath, reader.Token, nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, path, "", nil, http.StatusOK, &thread)
+	if len(thread) != 1 || !thread[0].Deleted || thread[0].Content != "" || len(thread[0].Replies) != 1 {
+		t.Fatalf("thread after the delete = %+v, want the deleted comment with its reply", thread)
+	}
+
+	// and is gone once the replies are
+	call(t, server, http.MethodDelete, fmt.Sprintf("%s/%d", path, reply.ID), author.Token, nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, path, "", nil, http.StatusOK, &thread)
+	if len(thread) != 0 {
+		t.Errorf("thread after deleting everything = %+v, want it empty", thread)
+	}
+	res = call(t, server, http.MethodPut, commentPath, reader.Token, &payload.UpdateCommentRequest{Content: "again"}, http.StatusNotFound, nil)
+	if res.Code != payload.ErrCommentNotFound.Code {
+		t.Errorf("edit of a deleted comment code = %q, want %q", res.Code, payload.ErrCommentNotFound.Code)
+	}
+}
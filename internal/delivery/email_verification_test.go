@@ -0,0 +1,60 @@
+package delivery_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func TestEmailVerification(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	if user.UserInfo.EmailVerified {
+		t.Fatal("email verified on register")
+	}
+
+	token := mailedToken(t, server, "user@example.com", 1)
+	verified := &payload.UserInfo{}
+	call(t, server, http.MethodPost, "/email/verify", "", &payload.VerifyEmailRequest{Token: token}, http.StatusOK, verified)
+	if !verified.EmailVerified {
+		t.Error("email not verified by the link")
+	}
+
+	info := &payload.UserInfo{}
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusOK, info)
+	if !info.EmailVerified {
+		t.Error("email not verified on /user")
+	}
+
+	res := call(t, server, http.MethodPost, "/email/verify/resend", user.Token, nil, http.StatusConflict, nil)
+	if res.Code != payload.ErrEmailVerified.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrEmailVerified.Code)
+	}
+}
+
+func TestEmailVerificationRejections(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	oldToken := mailedToken(t, server, "user@example.com", 1)
+
+	res := call(t, server, http.MethodPost, "/email/verify", "", &payload.VerifyEmailRequest{Token: "forged-token"}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrVerifyTokenInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrVerifyTokenInvalid.Code)
+	}
+
+	// the link sent to the old address stops working once the email changes
+	email := "new@example.com"
+	call(t, server, http.MethodPut, "/user", user.Token, &payload.UpdateUserRequest{Email: &email}, http.StatusOK, nil)
+	res = call(t, server, http.MethodPost, "/email/verify", "", &payload.VerifyEmailRequest{Token: oldToken}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrVerifyTokenInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrVerifyTokenInvalid.Code)
+	}
+
+	// resending is throttled
+	call(t, server, http.MethodPost, "/email/verify/resend", user.Token, nil, http.StatusOK, nil)
+	res = call(t, server, http.MethodPost, "/email/verify/resend", user.Token, nil, http.StatusTooManyRequests, nil)
+	if res.Code != payload.ErrVerifyThrottled.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrVerifyThrottled.Code)
+	}
+}
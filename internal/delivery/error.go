@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/haikalvidya/go-article/pkg/apperror"
+	"github.com/haikalvidya/go-article/pkg/common"
+	"github.com/haikalvidya/go-article/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler renders the errors returned by the handlers and middlewares,
+// app errors get the status and code of their kind, echo errors keep their
+// status and anything else is an internal error that is logged but not shown
+func HTTPErrorHandler(log logger.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status, res := errorResponse(err)
+		if status == http.StatusInternalServerError {
+			log.Errorf("%s %s: %v", c.Request().Method, c.Request().URL.Path, err)
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, res)
+		}
+		if err != nil {
+			log.Errorf("Error writing the error response: %v", err)
+		}
+	}
+}
+
+func errorResponse(err error) (int, *common.Response) {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if m, ok := httpErr.Message.(string); ok {
+			message = m
+		} else if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+
+		return httpErr.Code, &common.Response{
+			Status:  false,
+			Message: message,
+			Code:    statusCode(httpErr.Code),
+		}
+	}
+
+	appErr := apperror.From(err)
+	return appErr.HTTPStatus(), &common.Response{
+		Status:  false,
+		Message: appErr.Message,
+		Code:    appErr.Code,
+		Error:   appErr.Details,
+	}
+}
+
+// the code of an echo error is its status text, like not_found
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "http_error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -0,0 +1,134 @@
+package delivery_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// the response of the api with the data left raw to be decoded by the test
+type response struct {
+	Status  bool               `json:"status"`
+	Message string             `json:"messages"`
+	Code    string             `json:"code"`
+	Meta    *common.Pagination `json:"meta"`
+	Data    json.RawMessage    `json:"data"`
+}
+
+func newServer(t *testing.T, options ...func(cfg *config.Config)) *apptest.Server {
+	t.Helper()
+
+	server, err := apptest.NewServer(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+type backend struct {
+	name      string
+	newServer func(t *testing.T) *apptest.Server
+}
+
+// the repositories the api can run on, sqlite_test.go adds sqlite when the
+// tests are built with cgo
+var backends = []backend{
+	{"memory", func(t *testing.T) *apptest.Server { return newServer(t) }},
+}
+
+// run the test on every backend so the sql repositories are checked to behave
+// like the memory ones
+func runOnBackends(t *testing.T, test func(t *testing.T, server *apptest.Server)) {
+	for _, backend := range backends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.newServer(t))
+		})
+	}
+}
+
+// call the api and decode the data into data when it is not nil, the status
+// of the response has to be the wanted one
+func call(t *testing.T, server *apptest.Server, method string, path string, token string, body interface{}, status int, data interface{}) *response {
+	t.Helper()
+
+	header := http.Header{}
+	if token != "" {
+		header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	return callWithHeader(t, server, method, path, header, body, status, data)
+}
+
+// like call with the headers given as they are
+func callWithHeader(t *testing.T, server *apptest.Server, method string, path string, header http.Header, body interface{}, status int, data interface{}) *response {
+	t.Helper()
+
+	res := &response{}
+	got, err := server.DoWithHeader(method, path, header, body, res)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if got != status {
+		t.Fatalf("%s %s = %d %s (%s), want %d", method, path, got, res.Code, res.Message, status)
+	}
+
+	if data != nil {
+		err = json.Unmarshal(res.Data, data)
+		if err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, path, res.Data, err)
+		}
+	}
+	return res
+}
+
+func register(t *testing.T, server *apptest.Server, name string, email string) *payload.UserWithTokenResponse {
+	t.Helper()
+
+	user := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/register", "", &payload.RegisterUserRequest{
+		Name:                 name,
+		Email:                email,
+		Password:             "password",
+		PasswordConfirmation: "password",
+	}, http.StatusOK, user)
+	return user
+}
+
+var tokenInLink = regexp.MustCompile(`token=([^\s]+)`)
+
+// the token in the link of the count-th email sent to the address, some
+// emails are sent after the response so it waits for them a little
+func mailedToken(t *testing.T, server *apptest.Server, to string, count int) string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	messages := server.Mailer.Messages(to)
+	for len(messages) < count && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		messages = server.Mailer.Messages(to)
+	}
+	if len(messages) < count {
+		t.Fatalf("%d emails sent to %s, want %d", len(messages), to, count)
+	}
+
+	match := tokenInLink.FindStringSubmatch(messages[count-1].Body)
+	if match == nil {
+		t.Fatalf("no token in the email to %s: %q", to, messages[count-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,134 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+type internalDelivery deliveryType
+
+func (d *internalDelivery) GetUserByEmail(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.InternalUserQuery{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := d.Usecase.Internal.GetUserByEmail(c.Request().Context(), req.Email)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get User"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) GetUserByID(c echo.Context) error {
+	res := common.Response{}
+
+	user, err := d.Usecase.Internal.GetUserByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get User"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) LogoutUser(c echo.Context) error {
+	res := common.Response{}
+
+	err := d.Usecase.Internal.LogoutUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Logout User"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) BanUser(c echo.Context) error {
+	res := common.Response{}
+
+	user, err := d.Usecase.Internal.BanUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Ban User"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) UnbanUser(c echo.Context) error {
+	res := common.Response{}
+
+	user, err := d.Usecase.Internal.UnbanUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Unban User"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) TakeDownArticle(c echo.Context) error {
+	res := common.Response{}
+
+	// convert string to int
+	articleID, _ := strconv.Atoi(c.Param("id"))
+
+	article, err := d.Usecase.Internal.TakeDownArticle(c.Request().Context(), articleID)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Take Down Article"
+	res.Data = article
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) FlushCache(c echo.Context) error {
+	res := common.Response{}
+
+	flushed, err := d.Usecase.Internal.FlushCache(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Flush Cache"
+	res.Data = flushed
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *internalDelivery) GetStats(c echo.Context) error {
+	res := common.Response{}
+
+	stats, err := d.Usecase.Internal.GetStats(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Stats"
+	res.Data = stats
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,67 @@
+package delivery_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func callInternal(t *testing.T, server *apptest.Server, method string, path string, status int, data interface{}) *response {
+	t.Helper()
+
+	header := http.Header{}
+	header.Set("x-internal-token", server.Config.Server.InternalAccessKey)
+	return callWithHeader(t, server, method, path, header, nil, status, data)
+}
+
+func TestInternalBanAndTakeDown(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	article := createArticle(t, server, user.Token, "Title")
+
+	found := &payload.InternalUserInfo{}
+	callInternal(t, server, http.MethodGet, "/internal/users?email="+url.QueryEscape("user@example.com"), http.StatusOK, found)
+	if found.ID != user.UserInfo.ID || found.SessionCount != 1 {
+		t.Errorf("user = %+v, want %q with one session", found, user.UserInfo.ID)
+	}
+
+	// banning signs the user out and blocks the login until the unban
+	callInternal(t, server, http.MethodPost, "/internal/users/"+user.UserInfo.ID+"/ban", http.StatusOK, nil)
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusUnauthorized, nil)
+	res := login(t, server, "user@example.com", "password", http.StatusForbidden)
+	if res.Code != payload.ErrUserBanned.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrUserBanned.Code)
+	}
+	callInternal(t, server, http.MethodPost, "/internal/users/"+user.UserInfo.ID+"/unban", http.StatusOK, nil)
+	login(t, server, "user@example.com", "password", http.StatusOK)
+
+	id := strconv.Itoa(article.ID)
+	callInternal(t, server, http.MethodPost, "/internal/articles/"+id+"/takedown", http.StatusOK, nil)
+	call(t, server, http.MethodGet, "/article/"+id, "", nil, http.StatusNotFound, nil)
+
+	stats := &payload.StatsInfo{}
+	callInternal(t, server, http.MethodGet, "/internal/stats", http.StatusOK, stats)
+	if stats.Users.Total != 1 || stats.Articles.ByStatus["taken_down"] != 1 {
+		t.Errorf("stats = %+v %+v, want one user and one taken down article", stats.Users, stats.Articles)
+	}
+}
+
+func TestInternalAccessDenied(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	// a user token is not enough for the internal api
+	res := call(t, server, http.MethodGet, "/internal/stats", user.Token, nil, http.StatusUnauthorized, nil)
+	if res.Code != "internal_access_denied" {
+		t.Errorf("code = %q, want internal_access_denied", res.Code)
+	}
+
+	header := http.Header{}
+	header.Set("x-internal-token", "wrong-key")
+	callWithHeader(t, server, http.MethodPost, "/internal/users/"+user.UserInfo.ID+"/ban", header, nil, http.StatusUnauthorized, nil)
+	login(t, server, "user@example.com", "password", http.StatusOK)
+}
@@ -0,0 +1,14 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// the jwks is returned as is and not in the common response since the jwt
+// libraries of other services read this format
+func (d *userDelivery) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=60")
+	return c.JSON(http.StatusOK, d.Middleware.JWT.GetJWKS())
+}
@@ -0,0 +1,70 @@
+package delivery_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/pkg/middleware"
+)
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	key, err := middleware.GenerateJwtKey(dir, middleware.JWT_ALG_RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newServer(t, func(cfg *config.Config) {
+		cfg.JWT.Algorithm = middleware.JWT_ALG_RS256
+		cfg.JWT.KeyDir = dir
+	})
+
+	jwks := struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}{}
+	status, err := server.Do(http.MethodGet, "/.well-known/jwks.json", "", nil, &jwks)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("GET /.well-known/jwks.json = %d, %v", status, err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0]["kid"] != key.ID {
+		t.Fatalf("keys = %v, want the key %s", jwks.Keys, key.ID)
+	}
+	for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+		if _, ok := jwks.Keys[0][private]; ok {
+			t.Errorf("the key has the private field %q", private)
+		}
+	}
+
+	// the tokens are signed with the key and accepted by the api
+	user := register(t, server, "User", "user@example.com")
+	header := map[string]interface{}{}
+	data, err := base64.RawURLEncoding.DecodeString(strings.Split(user.Token, ".")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal(data, &header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header["alg"] != middleware.JWT_ALG_RS256 || header["kid"] != key.ID {
+		t.Errorf("token header = %v, want RS256 with the key %s", header, key.ID)
+	}
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusOK, nil)
+}
+
+func TestJWKSEmptyWithSharedSecret(t *testing.T) {
+	server := newServer(t)
+
+	jwks := &middleware.JWKS{}
+	_, err := server.Do(http.MethodGet, "/.well-known/jwks.json", "", nil, jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwks.Keys == nil || len(jwks.Keys) != 0 {
+		t.Errorf("keys = %v, want an empty list", jwks.Keys)
+	}
+}
@@ -0,0 +1,94 @@
+package delivery_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func login(t *testing.T, server *apptest.Server, email string, password string, status int) *response {
+	t.Helper()
+	return call(t, server, http.MethodPost, "/login", "", &payload.LoginUserRequest{Email: email, Password: password}, status, nil)
+}
+
+// how long the throttle key is locked, rounded up to the minute
+func lockedFor(t *testing.T, server *apptest.Server, key string) time.Duration {
+	t.Helper()
+
+	duration, err := server.Repo.Throttle.LockedFor(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return duration.Round(time.Minute)
+}
+
+func TestLoginLocksAccountAfterFiveFailures(t *testing.T) {
+	server := newServer(t)
+	register(t, server, "User", "user@example.com")
+
+	for i := 0; i < 5; i++ {
+		login(t, server, "user@example.com", "wrong-password", http.StatusUnauthorized)
+	}
+	if got := lockedFor(t, server, "LOGIN_EMAIL_user@example.com"); got != 0 {
+		t.Fatalf("locked for %s after five failures, want no lock", got)
+	}
+
+	// the sixth failure locks for a minute, the lock covers the right password too
+	login(t, server, "User@Example.com", "wrong-password", http.StatusUnauthorized)
+	if got := lockedFor(t, server, "LOGIN_EMAIL_user@example.com"); got != time.Minute {
+		t.Errorf("locked for %s after six failures, want 1m", got)
+	}
+
+	res := login(t, server, "user@example.com", "password", http.StatusTooManyRequests)
+	if res.Code != payload.ErrLoginLocked.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrLoginLocked.Code)
+	}
+
+	// unknown emails are locked the same way
+	for i := 0; i < 6; i++ {
+		login(t, server, "nobody@example.com", "wrong-password", http.StatusUnauthorized)
+	}
+	login(t, server, "nobody@example.com", "wrong-password", http.StatusTooManyRequests)
+}
+
+func TestLoginSuccessResetsAccountFailures(t *testing.T) {
+	server := newServer(t)
+	register(t, server, "User", "user@example.com")
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 5; i++ {
+			login(t, server, "user@example.com", "wrong-password", http.StatusUnauthorized)
+		}
+		login(t, server, "user@example.com", "password", http.StatusOK)
+	}
+}
+
+func TestLoginLocksIPAfterTwentyFailures(t *testing.T) {
+	server := newServer(t)
+	register(t, server, "User", "user@example.com")
+
+	// four failures per email stay under the account limit
+	for i := 0; i < 20; i++ {
+		login(t, server, fmt.Sprintf("user%d@example.com", i/4), "wrong-password", http.StatusUnauthorized)
+	}
+	login(t, server, "user@example.com", "password", http.StatusOK)
+	if got := lockedFor(t, server, "LOGIN_IP_127.0.0.1"); got != 0 {
+		t.Fatalf("ip locked for %s after twenty failures, want no lock", got)
+	}
+
+	// a successful login does not reset the failures of the ip
+	login(t, server, "user5@example.com", "wrong-password", http.StatusUnauthorized)
+	if got := lockedFor(t, server, "LOGIN_IP_127.0.0.1"); got != time.Minute {
+		t.Errorf("ip locked for %s after twenty one failures, want 1m", got)
+	}
+
+	res := login(t, server, "user@example.com", "password", http.StatusTooManyRequests)
+	if res.Code != payload.ErrLoginLocked.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrLoginLocked.Code)
+	}
+}
@@ -2,14 +2,20 @@ package delivery
 
 import (
 	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/policy"
 	"github.com/haikalvidya/go-article/internal/usecase"
 
 	"github.com/labstack/echo/v4"
 )
 
 type Delivery struct {
-	User    *userDelivery
-	Article *articleDelivery
+	User            *userDelivery
+	Article         *articleDelivery
+	ArticleRevision *articleRevisionDelivery
+	Tag             *tagDelivery
+	Comment         *commentDelivery
+	Internal        *internalDelivery
 }
 
 type deliveryType struct {
@@ -23,10 +29,16 @@ func NewDelivery(e *echo.Echo, usecase *usecase.Usecase, mid *middlewares.Custom
 		Middleware: mid,
 	}
 	delivery := &Delivery{
-		User:    (*userDelivery)(deliveryType),
-		Article: (*articleDelivery)(deliveryType),
+		User:            (*userDelivery)(deliveryType),
+		Article:         (*articleDelivery)(deliveryType),
+		ArticleRevision: (*articleRevisionDelivery)(deliveryType),
+		Tag:             (*tagDelivery)(deliveryType),
+		Comment:         (*commentDelivery)(deliveryType),
+		Internal:        (*internalDelivery)(deliveryType),
 	}
 
+	e.HTTPErrorHandler = HTTPErrorHandler(mid.Logger)
+
 	Route(e, delivery, mid)
 
 	return delivery
@@ -35,23 +47,85 @@ func NewDelivery(e *echo.Echo, usecase *usecase.Usecase, mid *middlewares.Custom
 func Route(e *echo.Echo, delivery *Delivery, mid *middlewares.CustomMiddleware) {
 	e.POST("/register", delivery.User.RegisterUser)
 	e.POST("/login", delivery.User.LoginUser)
+	e.POST("/login/2fa", delivery.User.LoginTwoFactor)
+	e.GET("/oidc/login", delivery.User.StartOIDCLogin)
+	e.POST("/oidc/callback", delivery.User.LoginOIDC)
 	e.POST("/logout", delivery.User.LogoutUser, mid.JWT.ValidateJWT())
+	e.POST("/token/refresh", delivery.User.RefreshToken)
+	e.POST("/password/forgot", delivery.User.ForgotPassword)
+	e.POST("/password/reset", delivery.User.ResetPassword)
+	e.POST("/email/verify", delivery.User.VerifyEmail)
+	e.POST("/email/verify/resend", delivery.User.ResendVerificationEmail, mid.JWT.ValidateJWT())
+	e.GET("/.well-known/jwks.json", delivery.User.GetJWKS)
 
 	// user
 	user := e.Group("/user")
 	{
-		user.GET("", delivery.User.GetUser, mid.JWT.ValidateJWT())
+		user.GET("", delivery.User.GetUser, mid.Authenticate(models.API_KEY_SCOPE_READ))
 		user.PUT("", delivery.User.UpdateUser, mid.JWT.ValidateJWT())
 		user.DELETE("", delivery.User.DeleteUser, mid.JWT.ValidateJWT())
+		user.GET("/sessions", delivery.User.GetSessions, mid.JWT.ValidateJWT())
+		user.DELETE("/sessions", delivery.User.RevokeAllSessions, mid.JWT.ValidateJWT())
+		user.DELETE("/sessions/:id", delivery.User.RevokeSession, mid.JWT.ValidateJWT())
+		user.POST("/2fa/enroll", delivery.User.EnrollTwoFactor, mid.JWT.ValidateJWT())
+		user.POST("/2fa/confirm", delivery.User.ConfirmTwoFactor, mid.JWT.ValidateJWT())
+		user.POST("/2fa/disable", delivery.User.DisableTwoFactor, mid.JWT.ValidateJWT())
+		user.GET("/articles", delivery.Article.GetMyArticles, mid.Authenticate(models.API_KEY_SCOPE_READ))
+		user.GET("/articles/:id", delivery.Article.GetMyArticleByID, mid.Authenticate(models.API_KEY_SCOPE_READ))
+		user.GET("/api-keys", delivery.User.GetAPIKeys, mid.JWT.ValidateJWT())
+		user.POST("/api-keys", delivery.User.CreateAPIKey, mid.JWT.ValidateJWT())
+		user.DELETE("/api-keys/:id", delivery.User.RevokeAPIKey, mid.JWT.ValidateJWT())
+	}
+
+	// user management
+	users := e.Group("/users", mid.JWT.ValidateJWT(), mid.RequirePermission(policy.PERMISSION_USER_MANAGE))
+	{
+		users.GET("", delivery.User.GetUsers)
+		users.PUT("/:id/role", delivery.User.UpdateUserRole)
+		users.DELETE("/:id", delivery.User.DeleteUserByID)
+	}
+
+	// internal api for the ops tooling, authenticated with the x-internal-token header
+	internal := e.Group("/internal", mid.InternalAccess.ValidateInternalAccess)
+	{
+		internal.GET("/users", delivery.Internal.GetUserByEmail)
+		internal.GET("/users/:id", delivery.Internal.GetUserByID)
+		internal.POST("/users/:id/logout", delivery.Internal.LogoutUser)
+		internal.POST("/users/:id/ban", delivery.Internal.BanUser)
+		internal.POST("/users/:id/unban", delivery.Internal.UnbanUser)
+		internal.POST("/articles/:id/takedown", delivery.Internal.TakeDownArticle)
+		internal.DELETE("/cache", delivery.Internal.FlushCache)
+		internal.GET("/stats", delivery.Internal.GetStats)
 	}
 
-	// article
+	// tag
+	e.GET("/tags", delivery.Tag.GetAllTags)
+
+	// article, the routes that take mid.Authenticate also accept an api key with the scope
 	article := e.Group("/article")
 	{
 		article.GET("", delivery.Article.GetAllArticle)
 		article.GET("/:id", delivery.Article.GetArticleByID)
-		article.POST("", delivery.Article.CreateArticle, mid.JWT.ValidateJWT())
-		article.PUT("/:id", delivery.Article.UpdateArticle, mid.JWT.ValidateJWT())
-		article.DELETE("/:id", delivery.Article.DeleteArticle, mid.JWT.ValidateJWT())
+		article.POST("", delivery.Article.CreateArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES), mid.RequirePermission(policy.PERMISSION_ARTICLE_WRITE))
+		article.PUT("/:id", delivery.Article.UpdateArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+		article.DELETE("/:id", delivery.Article.DeleteArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+		article.POST("/:id/publish", delivery.Article.PublishArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+		article.POST("/:id/unpublish", delivery.Article.UnpublishArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+		article.POST("/:id/archive", delivery.Article.ArchiveArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+		article.POST("/:id/schedule", delivery.Article.ScheduleArticle, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+
+		// revision
+		article.GET("/:id/revisions", delivery.ArticleRevision.GetRevisions, mid.Authenticate(models.API_KEY_SCOPE_READ))
+		article.GET("/:id/revisions/diff", delivery.ArticleRevision.DiffRevisions, mid.Authenticate(models.API_KEY_SCOPE_READ))
+		article.GET("/:id/revisions/:rev", delivery.ArticleRevision.GetRevision, mid.Authenticate(models.API_KEY_SCOPE_READ))
+		article.POST("/:id/revisions/:rev/restore", delivery.ArticleRevision.RestoreRevision, mid.Authenticate(models.API_KEY_SCOPE_WRITE_ARTICLES))
+
+		// comment
+		article.GET("/:id/comments", delivery.Comment.GetComments)
+		article.POST("/:id/comments", delivery.Comment.CreateComment, mid.JWT.ValidateJWT())
+		article.PUT("/:id/comments/:comment_id", delivery.Comment.UpdateComment, mid.JWT.ValidateJWT())
+		article.DELETE("/:id/comments/:comment_id", delivery.Comment.DeleteComment, mid.JWT.ValidateJWT())
+		article.POST("/:id/comments/:comment_id/hide", delivery.Comment.HideComment, mid.JWT.ValidateJWT())
+		article.POST("/:id/comments/:comment_id/unhide", delivery.Comment.UnhideComment, mid.JWT.ValidateJWT())
 	}
 }
@@ -0,0 +1,47 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// returns the url of the identity provider login page to send the user to
+func (d *userDelivery) StartOIDCLogin(c echo.Context) error {
+	res := common.Response{}
+
+	loginRes, err := d.Usecase.User.StartOIDCLogin(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Start Login"
+	res.Data = loginRes
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+// the page the provider redirects to posts the code and state here for the tokens
+func (d *userDelivery) LoginOIDC(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.OIDCCallbackRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	loginRes, err := d.Usecase.User.LoginOIDC(c.Request().Context(), req, getClientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Login"
+	res.Data = loginRes
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,161 @@
+package delivery_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/oidc/oidctest"
+)
+
+func newOIDCServer(t *testing.T) (*apptest.Server, *oidctest.Server) {
+	t.Helper()
+
+	provider, err := oidctest.NewServer("client", "client-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(provider.Close)
+
+	server := newServer(t, func(cfg *config.Config) {
+		cfg.OIDC = config.OIDCConfig{
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  "http://localhost/oidc/callback",
+		}
+	})
+	return server, provider
+}
+
+// start the login on the api and sign in at the provider, the code and state
+// are what the provider sends back to the redirect url
+func startOIDCLogin(t *testing.T, server *apptest.Server, provider *oidctest.Server) *payload.OIDCCallbackRequest {
+	t.Helper()
+
+	login := &payload.OIDCLoginResponse{}
+	call(t, server, http.MethodGet, "/oidc/login", "", nil, http.StatusOK, login)
+
+	code, state, err := provider.Authorize(login.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &payload.OIDCCallbackRequest{Code: code, State: state}
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	server, provider := newOIDCServer(t)
+	provider.SetUser(oidctest.User{Subject: "new", Email: "new@example.com", EmailVerified: true, Name: "New"})
+
+	first := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/oidc/callback", "", startOIDCLogin(t, server, provider), http.StatusOK, first)
+	if first.Token == "" || first.UserInfo.Email != "new@example.com" || !first.UserInfo.EmailVerified {
+		t.Errorf("login = %+v, want tokens of a verified new@example.com", first)
+	}
+
+	// the next login finds the user from the identity
+	second := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/oidc/callback", "", startOIDCLogin(t, server, provider), http.StatusOK, second)
+	if second.UserInfo.ID != first.UserInfo.ID {
+		t.Errorf("second login is user %s, want %s", second.UserInfo.ID, first.UserInfo.ID)
+	}
+}
+
+func TestOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	server, provider := newOIDCServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	provider.SetUser(oidctest.User{Subject: "linked", Email: "user@example.com", EmailVerified: true})
+
+	login := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/oidc/callback", "", startOIDCLogin(t, server, provider), http.StatusOK, login)
+	if login.UserInfo.ID != user.UserInfo.ID {
+		t.Errorf("login is user %s, want the registered user %s", login.UserInfo.ID, user.UserInfo.ID)
+	}
+	// the provider vouches for the email
+	if !login.UserInfo.EmailVerified {
+		t.Error("the email of the linked user is not verified")
+	}
+
+	identity, err := server.Repo.UserIdentity.SelectByIssuerAndSubject(context.Background(), provider.Issuer, "linked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != user.UserInfo.ID {
+		t.Errorf("identity is linked to %s, want %s", identity.UserID, user.UserInfo.ID)
+	}
+}
+
+// someone registered the email before its owner logged in with the provider,
+// they must lose the account once it is linked
+func TestOIDCLoginTakesOverUnverifiedUser(t *testing.T) {
+	server, provider := newOIDCServer(t)
+	attacker := register(t, server, "Attacker", "victim@example.com")
+	call(t, server, http.MethodPost, "/user/api-keys", attacker.Token, &payload.CreateAPIKeyRequest{Name: "kept", Scopes: []string{"read"}}, http.StatusOK, nil)
+
+	provider.SetUser(oidctest.User{Subject: "victim", Email: "victim@example.com", EmailVerified: true})
+
+	victim := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/oidc/callback", "", startOIDCLogin(t, server, provider), http.StatusOK, victim)
+	if victim.UserInfo.ID != attacker.UserInfo.ID || !victim.UserInfo.EmailVerified {
+		t.Fatalf("login = %+v, want the verified user %s", victim.UserInfo, attacker.UserInfo.ID)
+	}
+
+	// the session, password and api keys of the attacker are gone
+	call(t, server, http.MethodGet, "/user", attacker.Token, nil, http.StatusUnauthorized, nil)
+	login(t, server, "victim@example.com", "password", http.StatusUnauthorized)
+	keys, err := server.Repo.APIKey.SelectByUserID(context.Background(), victim.UserInfo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("%d api keys kept, want none", len(keys))
+	}
+
+	call(t, server, http.MethodGet, "/user", victim.Token, nil, http.StatusOK, nil)
+}
+
+func TestOIDCLoginDoesNotLinkUnverifiedEmail(t *testing.T) {
+	server, provider := newOIDCServer(t)
+	register(t, server, "User", "user@example.com")
+
+	provider.SetUser(oidctest.User{Subject: "attacker", Email: "user@example.com", EmailVerified: false})
+
+	res := call(t, server, http.MethodPost, "/oidc/callback", "", startOIDCLogin(t, server, provider), http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrOIDCEmailNotVerified.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrOIDCEmailNotVerified.Code)
+	}
+
+	_, err := server.Repo.UserIdentity.SelectByIssuerAndSubject(context.Background(), provider.Issuer, "attacker")
+	if err == nil {
+		t.Error("the unverified identity was linked")
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownState(t *testing.T) {
+	server, provider := newOIDCServer(t)
+	provider.SetUser(oidctest.User{Subject: "user", Email: "user@example.com", EmailVerified: true})
+
+	callback := startOIDCLogin(t, server, provider)
+
+	unknown := &payload.OIDCCallbackRequest{Code: callback.Code, State: "unknown-state"}
+	res := call(t, server, http.MethodPost, "/oidc/callback", "", unknown, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrOIDCStateInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrOIDCStateInvalid.Code)
+	}
+
+	// the state can only be used once
+	call(t, server, http.MethodPost, "/oidc/callback", "", callback, http.StatusOK, nil)
+	res = call(t, server, http.MethodPost, "/oidc/callback", "", callback, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrOIDCStateInvalid.Code {
+		t.Errorf("reused state code = %q, want %q", res.Code, payload.ErrOIDCStateInvalid.Code)
+	}
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+	server := newServer(t)
+	call(t, server, http.MethodGet, "/oidc/login", "", nil, http.StatusNotFound, nil)
+}
@@ -0,0 +1,54 @@
+package delivery_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func TestPasswordReset(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	call(t, server, http.MethodPost, "/password/forgot", "", &payload.ForgotPasswordRequest{Email: "user@example.com"}, http.StatusOK, nil)
+	// the first email is the verification one from the register
+	token := mailedToken(t, server, "user@example.com", 2)
+
+	reset := &payload.ResetPasswordRequest{Token: token, Password: "new-password", PasswordConfirmation: "new-password"}
+	call(t, server, http.MethodPost, "/password/reset", "", reset, http.StatusOK, nil)
+
+	// the old password and the sessions from before the reset stop working
+	login(t, server, "user@example.com", "password", http.StatusUnauthorized)
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusUnauthorized, nil)
+	login(t, server, "user@example.com", "new-password", http.StatusOK)
+
+	// the token can only be used once
+	res := call(t, server, http.MethodPost, "/password/reset", "", reset, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrResetTokenInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrResetTokenInvalid.Code)
+	}
+}
+
+func TestPasswordResetRejections(t *testing.T) {
+	server := newServer(t)
+	register(t, server, "User", "user@example.com")
+
+	// unknown emails get the same answer but no email
+	call(t, server, http.MethodPost, "/password/forgot", "", &payload.ForgotPasswordRequest{Email: "nobody@example.com"}, http.StatusOK, nil)
+	call(t, server, http.MethodPost, "/password/forgot", "", &payload.ForgotPasswordRequest{Email: "user@example.com"}, http.StatusOK, nil)
+	mailedToken(t, server, "user@example.com", 2)
+	if got := len(server.Mailer.Messages("nobody@example.com")); got != 0 {
+		t.Errorf("%d emails sent to an unknown address, want 0", got)
+	}
+
+	res := call(t, server, http.MethodPost, "/password/reset", "", &payload.ResetPasswordRequest{
+		Token:                "forged-token",
+		Password:             "new-password",
+		PasswordConfirmation: "new-password",
+	}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrResetTokenInvalid.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrResetTokenInvalid.Code)
+	}
+	login(t, server, "user@example.com", "password", http.StatusOK)
+}
@@ -0,0 +1,37 @@
+package payload
+
+import "github.com/haikalvidya/go-article/pkg/apperror"
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read write:articles"`
+}
+
+type APIKeyInfo struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// the key is only returned when it is created
+type CreateAPIKeyResponse struct {
+	*APIKeyInfo
+	Key string `json:"key"`
+}
+
+const (
+	ERROR_API_KEY_NOT_FOUND = "api key not found"
+	ERROR_API_KEY_INVALID   = "invalid api key"
+	ERROR_API_KEY_SCOPE     = "api key does not have the required scope"
+	ERROR_API_KEY_LIMIT     = "too many api keys, revoke one first"
+)
+
+var (
+	ErrAPIKeyNotFound = apperror.NotFound("api_key_not_found", ERROR_API_KEY_NOT_FOUND)
+	ErrAPIKeyInvalid  = apperror.Unauthorized("api_key_invalid", ERROR_API_KEY_INVALID)
+	ErrAPIKeyScope    = apperror.Forbidden("api_key_scope", ERROR_API_KEY_SCOPE)
+	ErrAPIKeyLimit    = apperror.Conflict("api_key_limit", ERROR_API_KEY_LIMIT)
+)
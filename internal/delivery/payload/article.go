@@ -1,32 +1,83 @@
 package payload
 
+import (
+	"time"
+
+	"github.com/haikalvidya/go-article/pkg/apperror"
+)
+
 type CreateArticleRequest struct {
-	Title   string `json:"title" validate:"required"`
-	Content string `json:"content" validate:"required"`
+	Title   string   `json:"title" validate:"required"`
+	Content string   `json:"content" validate:"required,max=100000"`
+	Status  string   `json:"status" validate:"omitempty,oneof=draft published"`
+	Tags    []string `json:"tags" validate:"omitempty,max=10,dive,required,max=50"`
 }
 
 type ArticleInfo struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	AuthorID  string    `json:"author_id"`
-	Author    *UserInfo `json:"author"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content,omitempty"`
+	Snippet      string    `json:"snippet,omitempty"`
+	Status       string    `json:"status"`
+	PublishedAt  string    `json:"published_at,omitempty"`
+	PublishAt    string    `json:"publish_at,omitempty"`
+	AuthorID     string    `json:"author_id"`
+	Author       *UserInfo `json:"author"`
+	Tags         []string  `json:"tags"`
+	CommentCount int64     `json:"comment_count"`
+	CreatedAt    string    `json:"created_at"`
+	UpdatedAt    string    `json:"updated_at"`
 }
 
 type UpdateArticleRequest struct {
 	Title   string `json:"title"`
-	Content string `json:"content"`
+	Content string `json:"content" validate:"max=100000"`
+	// nil keeps the current tags, an empty list removes them
+	Tags *[]string `json:"tags" validate:"omitempty,max=10,dive,required,max=50"`
 }
 
 type ArticleQuery struct {
-	AuthorName  string `query:"author"`
-	QuerySearch string `query:"query"`
+	AuthorName  string   `query:"author"`
+	QuerySearch string   `query:"query"`
+	Tags        []string `query:"tag"`
+	// and requires every tag to be present, or requires any of them
+	TagMode string `query:"tag_mode" validate:"omitempty,oneof=and or"`
+	Sort    string `query:"sort" validate:"omitempty,oneof=created_at -created_at title -title updated_at -updated_at"`
+}
+
+const (
+	TAG_MODE_AND = "and"
+	TAG_MODE_OR  = "or"
+)
+
+type ScheduleArticleRequest struct {
+	PublishAt time.Time `json:"publish_at" validate:"required"`
+}
+
+type MyArticleQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=draft scheduled published archived taken_down"`
+	Sort   string `query:"sort" validate:"omitempty,oneof=created_at -created_at title -title updated_at -updated_at"`
 }
 
 const (
 	ERROR_ARTICLE_NOT_FOUND   = "article not found"
 	ERROR_ARTICLE_NOT_ALLOWED = "article is not owned by author"
-	ERROR_GET_ARTICLE         = "error to get articles"
+
+	ERROR_ARTICLE_STATUS_TRANSITION = "article status can not be changed"
+	ERROR_ARTICLE_PUBLISH_AT_PAST   = "publish time must be in the future"
+
+	ERROR_CURSOR_INVALID = "invalid cursor"
+	ERROR_CURSOR_SORT    = "cursor pagination can only be sorted by created_at"
+
+	ERROR_SEARCH_QUERY_EMPTY = "search query must contain a word to look for"
+)
+
+var (
+	ErrArticleNotFound         = apperror.NotFound("article_not_found", ERROR_ARTICLE_NOT_FOUND)
+	ErrArticleNotAllowed       = apperror.Forbidden("article_not_allowed", ERROR_ARTICLE_NOT_ALLOWED)
+	ErrArticleStatusTransition = apperror.Conflict("article_status_transition", ERROR_ARTICLE_STATUS_TRANSITION)
+	ErrArticlePublishAtPast    = apperror.Validation("article_publish_at_past", ERROR_ARTICLE_PUBLISH_AT_PAST)
+	ErrCursorInvalid           = apperror.Validation("cursor_invalid", ERROR_CURSOR_INVALID)
+	ErrCursorSort              = apperror.Validation("cursor_sort", ERROR_CURSOR_SORT)
+	ErrSearchQueryEmpty        = apperror.Validation("search_query_empty", ERROR_SEARCH_QUERY_EMPTY)
 )
@@ -0,0 +1,41 @@
+package payload
+
+import "github.com/haikalvidya/go-article/pkg/apperror"
+
+type ArticleRevisionInfo struct {
+	ArticleID    int    `json:"article_id"`
+	Revision     int    `json:"revision"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	EditorID     string `json:"editor_id"`
+	RestoredFrom *int   `json:"restored_from,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type ArticleRevisionDiffQuery struct {
+	From int `query:"from" validate:"required,min=1"`
+	To   int `query:"to" validate:"required,min=1"`
+}
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type ArticleRevisionDiff struct {
+	ArticleID int         `json:"article_id"`
+	From      int         `json:"from"`
+	To        int         `json:"to"`
+	Title     []*DiffLine `json:"title"`
+	Content   []*DiffLine `json:"content"`
+}
+
+const (
+	ERROR_REVISION_NOT_FOUND      = "revision not found"
+	ERROR_REVISION_DIFF_TOO_LARGE = "the revisions are too different to diff"
+)
+
+var (
+	ErrRevisionNotFound     = apperror.NotFound("revision_not_found", ERROR_REVISION_NOT_FOUND)
+	ErrRevisionDiffTooLarge = apperror.Validation("revision_diff_too_large", ERROR_REVISION_DIFF_TOO_LARGE)
+)
@@ -0,0 +1,38 @@
+package payload
+
+import "github.com/haikalvidya/go-article/pkg/apperror"
+
+type CreateCommentRequest struct {
+	Content  string `json:"content" validate:"required,max=5000"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" validate:"required,max=5000"`
+}
+
+type CommentInfo struct {
+	ID        int            `json:"id"`
+	ArticleID int            `json:"article_id"`
+	ParentID  *int           `json:"parent_id"`
+	Content   string         `json:"content"`
+	AuthorID  string         `json:"author_id,omitempty"`
+	Author    *UserInfo      `json:"author,omitempty"`
+	Hidden    bool           `json:"hidden"`
+	Deleted   bool           `json:"deleted"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at,omitempty"`
+	Replies   []*CommentInfo `json:"replies"`
+}
+
+const (
+	ERROR_COMMENT_NOT_FOUND   = "comment not found"
+	ERROR_COMMENT_NOT_ALLOWED = "comment is not owned by user"
+	ERROR_COMMENT_PARENT      = "parent comment not found in this article"
+)
+
+var (
+	ErrCommentNotFound   = apperror.NotFound("comment_not_found", ERROR_COMMENT_NOT_FOUND)
+	ErrCommentNotAllowed = apperror.Forbidden("comment_not_allowed", ERROR_COMMENT_NOT_ALLOWED)
+	ErrCommentParent     = apperror.Validation("comment_parent", ERROR_COMMENT_PARENT)
+)
@@ -0,0 +1,38 @@
+package payload
+
+// user as seen by the ops tooling, with the account state that is hidden from other users
+type InternalUserInfo struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	EmailVerifiedAt string `json:"email_verified_at,omitempty"`
+	Role            string `json:"role"`
+	CreatedAt       string `json:"created_at"`
+	BannedAt        string `json:"banned_at,omitempty"`
+	SessionCount    int    `json:"session_count"`
+}
+
+type InternalUserQuery struct {
+	Email string `query:"email" validate:"required,email"`
+}
+
+type StatsInfo struct {
+	Users    *UserStats    `json:"users"`
+	Articles *ArticleStats `json:"articles"`
+	Comments int64         `json:"comments"`
+}
+
+type UserStats struct {
+	Total  int64            `json:"total"`
+	Banned int64            `json:"banned"`
+	ByRole map[string]int64 `json:"by_role"`
+}
+
+type ArticleStats struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+type CacheFlushInfo struct {
+	DeletedKeys int `json:"deleted_keys"`
+}
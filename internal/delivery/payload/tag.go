@@ -0,0 +1,6 @@
+package payload
+
+type TagInfo struct {
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"article_count"`
+}
@@ -1,5 +1,7 @@
 package payload
 
+import "github.com/haikalvidya/go-article/pkg/apperror"
+
 type RegisterUserRequest struct {
 	Name                 string `json:"name" validate:"required"`
 	Email                string `json:"email" validate:"required,email"`
@@ -7,9 +9,24 @@ type RegisterUserRequest struct {
 	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=4,max=100,eqfield=Password"`
 }
 
+// when two factor is on the login only returns the challenge token, the tokens
+// are given once the challenge is answered on /login/2fa
 type UserWithTokenResponse struct {
-	UserInfo *UserInfo `json:"user_info"`
-	Token    string    `json:"token"`
+	UserInfo     *UserInfo `json:"user_info,omitempty"`
+	Token        string    `json:"token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type LoginUserRequest struct {
@@ -24,19 +41,149 @@ type UpdateUserRequest struct {
 	PasswordConfirmation *string `json:"password_confirmation" validate:"omitempty,min=4,max=100,eqfield=Password"`
 }
 
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type SessionInfo struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+}
+
 type UserInfo struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	Role  string `json:"role"`
+
+	EmailVerified    bool `json:"email_verified"`
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `json:"token" validate:"required"`
+	Password             string `json:"password" validate:"required,min=4,max=100"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=4,max=100,eqfield=Password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	// png image of the uri as a data url
+	QRCode string `json:"qr_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	// code from the authenticator or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// code from the authenticator or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=reader author editor admin"`
+}
+
+const (
+	ERROR_USER_NOT_FOUND        = "user not found"
+	ERROR_USER_EXIST            = "user already exist"
+	ERROR_USER_INVALID          = "invalid user"
+	ERROR_WRONG_PASSWORD        = "wrong password"
+	ERROR_TOKEN_INVALID         = "invalid token"
+	ERROR_PASSWORD_NOT_MATCH    = "password not match"
+	ERROR_USER_NOT_LOGGED_IN    = "user not logged in"
+	ERROR_AUTHOR_NOT_FOUND      = "author not found"
+	ERROR_REFRESH_TOKEN_USED    = "refresh token already used, session revoked"
+	ERROR_SESSION_NOT_FOUND     = "session not found"
+	ERROR_PERMISSION_DENIED     = "permission denied"
+	ERROR_USER_NOT_ALLOWED      = "user can not be managed with your role"
+	ERROR_ROLE_NOT_ALLOWED      = "role can not be assigned with your role"
+	ERROR_INVALID_CREDENTIALS   = "invalid email or password"
+	ERROR_LOGIN_LOCKED          = "too many failed login attempts, try again later"
+	ERROR_USER_BANNED           = "user is banned"
+	ERROR_RESET_TOKEN_INVALID   = "invalid or expired password reset token"
+	ERROR_VERIFY_TOKEN_INVALID  = "invalid or expired email verification token"
+	ERROR_EMAIL_VERIFIED        = "email is already verified"
+	ERROR_EMAIL_NOT_VERIFIED    = "email must be verified first"
+	ERROR_VERIFY_THROTTLED      = "verification email was sent recently, try again later"
+	ERROR_2FA_ENABLED           = "two factor authentication is already enabled"
+	ERROR_2FA_NOT_ENABLED       = "two factor authentication is not enabled"
+	ERROR_2FA_NOT_ENROLLED      = "two factor authentication has to be enrolled first"
+	ERROR_2FA_CODE_INVALID      = "invalid two factor code"
+	ERROR_2FA_CHALLENGE_INVALID = "invalid or expired login challenge, log in again"
+)
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
 }
 
 const (
-	ERROR_USER_NOT_FOUND     = "user not found"
-	ERROR_USER_EXIST         = "user already exist"
-	ERROR_USER_INVALID       = "invalid user"
-	ERROR_WRONG_PASSWORD     = "wrong password"
-	ERROR_TOKEN_INVALID      = "invalid token"
-	ERROR_PASSWORD_NOT_MATCH = "password not match"
-	ERROR_USER_NOT_LOGGED_IN = "user not logged in"
-	ERROR_AUTHOR_NOT_FOUND   = "author not found"
+	ERROR_OIDC_NOT_CONFIGURED     = "login with the identity provider is not configured"
+	ERROR_OIDC_STATE_INVALID      = "invalid or expired login state, start the login again"
+	ERROR_OIDC_FAILED             = "login with the identity provider failed"
+	ERROR_OIDC_EMAIL_NOT_VERIFIED = "the identity provider did not verify the email"
+)
+
+var (
+	ErrUserNotFound              = apperror.NotFound("user_not_found", ERROR_USER_NOT_FOUND)
+	ErrUserExist                 = apperror.Conflict("user_exist", ERROR_USER_EXIST)
+	ErrUserInvalid               = apperror.Validation("user_invalid", ERROR_USER_INVALID)
+	ErrWrongPassword             = apperror.Unauthorized("wrong_password", ERROR_WRONG_PASSWORD)
+	ErrTokenInvalid              = apperror.Unauthorized("token_invalid", ERROR_TOKEN_INVALID)
+	ErrPasswordNotMatch          = apperror.Validation("password_not_match", ERROR_PASSWORD_NOT_MATCH)
+	ErrUserNotLoggedIn           = apperror.Unauthorized("user_not_logged_in", ERROR_USER_NOT_LOGGED_IN)
+	ErrAuthorNotFound            = apperror.NotFound("author_not_found", ERROR_AUTHOR_NOT_FOUND)
+	ErrRefreshTokenUsed          = apperror.Unauthorized("refresh_token_used", ERROR_REFRESH_TOKEN_USED)
+	ErrSessionNotFound           = apperror.NotFound("session_not_found", ERROR_SESSION_NOT_FOUND)
+	ErrPermissionDenied          = apperror.Forbidden("permission_denied", ERROR_PERMISSION_DENIED)
+	ErrUserNotAllowed            = apperror.Forbidden("user_not_allowed", ERROR_USER_NOT_ALLOWED)
+	ErrRoleNotAllowed            = apperror.Forbidden("role_not_allowed", ERROR_ROLE_NOT_ALLOWED)
+	ErrInvalidCredentials        = apperror.Unauthorized("invalid_credentials", ERROR_INVALID_CREDENTIALS)
+	ErrLoginLocked               = apperror.TooManyRequests("login_locked", ERROR_LOGIN_LOCKED)
+	ErrUserBanned                = apperror.Forbidden("user_banned", ERROR_USER_BANNED)
+	ErrResetTokenInvalid         = apperror.Validation("reset_token_invalid", ERROR_RESET_TOKEN_INVALID)
+	ErrVerifyTokenInvalid        = apperror.Validation("verify_token_invalid", ERROR_VERIFY_TOKEN_INVALID)
+	ErrEmailVerified             = apperror.Conflict("email_verified", ERROR_EMAIL_VERIFIED)
+	ErrEmailNotVerified          = apperror.Forbidden("email_not_verified", ERROR_EMAIL_NOT_VERIFIED)
+	ErrVerifyThrottled           = apperror.TooManyRequests("verify_throttled", ERROR_VERIFY_THROTTLED)
+	ErrTwoFactorEnabled          = apperror.Conflict("two_factor_enabled", ERROR_2FA_ENABLED)
+	ErrTwoFactorNotEnabled       = apperror.Conflict("two_factor_not_enabled", ERROR_2FA_NOT_ENABLED)
+	ErrTwoFactorNotEnrolled      = apperror.Conflict("two_factor_not_enrolled", ERROR_2FA_NOT_ENROLLED)
+	ErrTwoFactorCodeInvalid      = apperror.Validation("two_factor_code_invalid", ERROR_2FA_CODE_INVALID)
+	ErrTwoFactorChallengeInvalid = apperror.Unauthorized("two_factor_challenge_invalid", ERROR_2FA_CHALLENGE_INVALID)
+	ErrOIDCNotConfigured         = apperror.NotFound("oidc_not_configured", ERROR_OIDC_NOT_CONFIGURED)
+	ErrOIDCStateInvalid          = apperror.Unauthorized("oidc_state_invalid", ERROR_OIDC_STATE_INVALID)
+	ErrOIDCFailed                = apperror.Unauthorized("oidc_failed", ERROR_OIDC_FAILED)
+	ErrOIDCEmailNotVerified      = apperror.Unauthorized("oidc_email_not_verified", ERROR_OIDC_EMAIL_NOT_VERIFIED)
 )
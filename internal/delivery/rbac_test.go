@@ -0,0 +1,82 @@
+package delivery_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+// give the user the role and log in again, the role is carried in the jwt
+func withRole(t *testing.T, server *apptest.Server, email string, role string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	user, err := server.Repo.User.SelectByEmail(ctx, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Role = role
+	err = server.Repo.User.Update(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/login", "", &payload.LoginUserRequest{Email: email, Password: "password"}, http.StatusOK, res)
+	return res.Token
+}
+
+func TestAdminManagesUsers(t *testing.T) {
+	server := newServer(t)
+	register(t, server, "Admin", "admin@example.com")
+	author := register(t, server, "Author", "author@example.com")
+	admin := withRole(t, server, "admin@example.com", "admin")
+
+	users := []*payload.UserInfo{}
+	call(t, server, http.MethodGet, "/users", admin, nil, http.StatusOK, &users)
+	if len(users) != 2 {
+		t.Errorf("%d users listed, want 2", len(users))
+	}
+
+	updated := &payload.UserInfo{}
+	call(t, server, http.MethodPut, "/users/"+author.UserInfo.ID+"/role", admin, &payload.UpdateUserRoleRequest{Role: "editor"}, http.StatusOK, updated)
+	if updated.Role != "editor" {
+		t.Errorf("role = %q, want editor", updated.Role)
+	}
+
+	// the sessions with the old role are signed out
+	call(t, server, http.MethodGet, "/user", author.Token, nil, http.StatusUnauthorized, nil)
+}
+
+func TestRolePermissions(t *testing.T) {
+	server := newServer(t)
+	author := register(t, server, "Author", "author@example.com")
+	register(t, server, "Editor", "editor@example.com")
+	register(t, server, "Reader", "reader@example.com")
+	editor := withRole(t, server, "editor@example.com", "editor")
+	reader := withRole(t, server, "reader@example.com", "reader")
+
+	res := call(t, server, http.MethodGet, "/users", author.Token, nil, http.StatusForbidden, nil)
+	if res.Code != payload.ErrPermissionDenied.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrPermissionDenied.Code)
+	}
+
+	// editors manage users but can not hand out roles
+	call(t, server, http.MethodGet, "/users", editor, nil, http.StatusOK, nil)
+	res = call(t, server, http.MethodPut, "/users/"+author.UserInfo.ID+"/role", editor, &payload.UpdateUserRoleRequest{Role: "reader"}, http.StatusForbidden, nil)
+	if res.Code != payload.ErrRoleNotAllowed.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrRoleNotAllowed.Code)
+	}
+
+	res = call(t, server, http.MethodPost, "/article", reader, &payload.CreateArticleRequest{
+		Title:   "Title",
+		Content: "content",
+		Status:  "published",
+	}, http.StatusForbidden, nil)
+	if res.Code != payload.ErrPermissionDenied.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrPermissionDenied.Code)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build cgo
+
+package delivery_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+)
+
+func init() {
+	backends = append(backends, backend{"sqlite", newSQLiteServer})
+}
+
+func newSQLiteServer(t *testing.T) *apptest.Server {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "article.db")
+	server, err := apptest.NewSQLiteServer(path, filepath.Join("..", "..", "migrations", "sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
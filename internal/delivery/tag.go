@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+type tagDelivery deliveryType
+
+// get all tags with their usage
+func (d *tagDelivery) GetAllTags(c echo.Context) error {
+	res := common.Response{}
+
+	tagRes, err := d.Usecase.Tag.GetAllTags(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get All Tags"
+	res.Data = tagRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// second step of the login, exchanges the challenge and a code for the tokens
+func (d *userDelivery) LoginTwoFactor(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.TwoFactorLoginRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	loginRes, err := d.Usecase.User.LoginTwoFactor(c.Request().Context(), req, getClientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Login"
+	res.Data = loginRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) EnrollTwoFactor(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	enrollRes, err := d.Usecase.User.EnrollTwoFactor(c.Request().Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Enroll Two Factor"
+	res.Data = enrollRes
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) ConfirmTwoFactor(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.TwoFactorCodeRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	codes, err := d.Usecase.User.ConfirmTwoFactor(c.Request().Context(), userId, req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Confirm Two Factor"
+	res.Data = codes
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) DisableTwoFactor(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.TwoFactorDisableRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.DisableTwoFactor(c.Request().Context(), userId, req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Disable Two Factor"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,229 @@
+package delivery_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+
+	"github.com/pquerna/otp/totp"
+)
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := totp.GenerateCode(secret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// turn two factor on for the user, the secret and recovery codes are returned
+func enableTwoFactor(t *testing.T, server *apptest.Server, token string) (string, []string) {
+	t.Helper()
+
+	enroll := &payload.TwoFactorEnrollResponse{}
+	call(t, server, http.MethodPost, "/user/2fa/enroll", token, nil, http.StatusOK, enroll)
+
+	codes := &payload.RecoveryCodesResponse{}
+	call(t, server, http.MethodPost, "/user/2fa/confirm", token, &payload.TwoFactorCodeRequest{Code: totpCode(t, enroll.Secret, time.Now())}, http.StatusOK, codes)
+	return enroll.Secret, codes.RecoveryCodes
+}
+
+// log in with the password, two factor is on so only a challenge comes back
+func startTwoFactorLogin(t *testing.T, server *apptest.Server, email string) string {
+	t.Helper()
+
+	login := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/login", "", &payload.LoginUserRequest{Email: email, Password: "password"}, http.StatusOK, login)
+	if !login.TwoFactorRequired || login.ChallengeToken == "" || login.Token != "" {
+		t.Fatalf("login = %+v, want only a challenge", login)
+	}
+	return login.ChallengeToken
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	enroll := &payload.TwoFactorEnrollResponse{}
+	call(t, server, http.MethodPost, "/user/2fa/enroll", user.Token, nil, http.StatusOK, enroll)
+	if enroll.Secret == "" || !strings.HasPrefix(enroll.URI, "otpauth://totp/") || !strings.HasPrefix(enroll.QRCode, "data:image/png;base64,") {
+		t.Fatalf("enroll = %+v", enroll)
+	}
+
+	// the secret is only kept encrypted
+	stored, err := server.Repo.User.SelectByID(context.Background(), user.UserInfo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.TotpSecret == nil || strings.Contains(*stored.TotpSecret, enroll.Secret) {
+		t.Errorf("stored secret = %v, want the encrypted secret", stored.TotpSecret)
+	}
+
+	// two factor is off until the enrollment is confirmed
+	login := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/login", "", &payload.LoginUserRequest{Email: "user@example.com", Password: "password"}, http.StatusOK, login)
+	if login.TwoFactorRequired || login.Token == "" {
+		t.Errorf("login before the confirmation = %+v, want tokens", login)
+	}
+
+	res := call(t, server, http.MethodPost, "/user/2fa/confirm", user.Token, &payload.TwoFactorCodeRequest{Code: "000000"}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrTwoFactorCodeInvalid.Code {
+		t.Errorf("wrong code = %q, want %q", res.Code, payload.ErrTwoFactorCodeInvalid.Code)
+	}
+
+	codes := &payload.RecoveryCodesResponse{}
+	call(t, server, http.MethodPost, "/user/2fa/confirm", user.Token, &payload.TwoFactorCodeRequest{Code: totpCode(t, enroll.Secret, time.Now())}, http.StatusOK, codes)
+	if len(codes.RecoveryCodes) != 10 {
+		t.Errorf("got %d recovery codes, want 10", len(codes.RecoveryCodes))
+	}
+
+	res = call(t, server, http.MethodPost, "/user/2fa/enroll", user.Token, nil, http.StatusConflict, nil)
+	if res.Code != payload.ErrTwoFactorEnabled.Code {
+		t.Errorf("enroll again = %q, want %q", res.Code, payload.ErrTwoFactorEnabled.Code)
+	}
+}
+
+func TestTwoFactorLoginRejectsReplayedCode(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	secret, _ := enableTwoFactor(t, server, user.Token)
+
+	// the code of the next period is valid too and was not used by the confirmation
+	code := totpCode(t, secret, time.Now().Add(30*time.Second))
+
+	login := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           code,
+	}, http.StatusOK, login)
+	if login.Token == "" || login.RefreshToken == "" {
+		t.Fatalf("login = %+v, want tokens", login)
+	}
+
+	res := call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           code,
+	}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrTwoFactorCodeInvalid.Code {
+		t.Errorf("replayed code = %q, want %q", res.Code, payload.ErrTwoFactorCodeInvalid.Code)
+	}
+}
+
+func TestTwoFactorRecoveryCodesWorkOnce(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	_, recoveryCodes := enableTwoFactor(t, server, user.Token)
+
+	// the case and the dash do not matter
+	code := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           code,
+	}, http.StatusOK, nil)
+
+	res := call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           recoveryCodes[0],
+	}, http.StatusBadRequest, nil)
+	if res.Code != payload.ErrTwoFactorCodeInvalid.Code {
+		t.Errorf("used recovery code = %q, want %q", res.Code, payload.ErrTwoFactorCodeInvalid.Code)
+	}
+
+	// the other codes still work
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           recoveryCodes[1],
+	}, http.StatusOK, nil)
+}
+
+func TestTwoFactorChallengeAllowsFiveWrongCodes(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	secret, _ := enableTwoFactor(t, server, user.Token)
+
+	challenge := startTwoFactorLogin(t, server, "user@example.com")
+	for i := 0; i < 5; i++ {
+		call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{ChallengeToken: challenge, Code: "aaaaa-bbbbb"}, http.StatusBadRequest, nil)
+	}
+
+	res := call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: challenge,
+		Code:           totpCode(t, secret, time.Now().Add(30*time.Second)),
+	}, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrTwoFactorChallengeInvalid.Code {
+		t.Errorf("code after five wrong ones = %q, want %q", res.Code, payload.ErrTwoFactorChallengeInvalid.Code)
+	}
+}
+
+// a known password must not give unlimited guesses of the code by starting
+// new challenges, the wrong codes lock the account like wrong passwords
+func TestTwoFactorWrongCodesLockTheAccount(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	_, recoveryCodes := enableTwoFactor(t, server, user.Token)
+
+	wrongCode := func(status int) *response {
+		return call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+			ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+			Code:           "aaaaa-bbbbb",
+		}, status, nil)
+	}
+
+	// a right code resets the failures, the right password alone does not
+	for i := 0; i < 5; i++ {
+		wrongCode(http.StatusBadRequest)
+	}
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           recoveryCodes[0],
+	}, http.StatusOK, nil)
+
+	for i := 0; i < 5; i++ {
+		wrongCode(http.StatusBadRequest)
+	}
+	if got := lockedFor(t, server, "LOGIN_EMAIL_user@example.com"); got != 0 {
+		t.Fatalf("locked for %s after five wrong codes, want no lock", got)
+	}
+
+	challenge := startTwoFactorLogin(t, server, "user@example.com")
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{ChallengeToken: challenge, Code: "aaaaa-bbbbb"}, http.StatusBadRequest, nil)
+	if got := lockedFor(t, server, "LOGIN_EMAIL_user@example.com"); got != time.Minute {
+		t.Errorf("locked for %s after six wrong codes, want 1m", got)
+	}
+
+	// the lock covers the password and the challenge already handed out
+	login(t, server, "user@example.com", "password", http.StatusTooManyRequests)
+	res := call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{ChallengeToken: challenge, Code: recoveryCodes[1]}, http.StatusTooManyRequests, nil)
+	if res.Code != payload.ErrLoginLocked.Code {
+		t.Errorf("code = %q, want %q", res.Code, payload.ErrLoginLocked.Code)
+	}
+}
+
+// secrets saved before they were encrypted are still read
+func TestTwoFactorPlaintextSecret(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+	secret, _ := enableTwoFactor(t, server, user.Token)
+
+	stored, err := server.Repo.User.SelectByID(context.Background(), user.UserInfo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.TotpSecret = &secret
+	err = server.Repo.User.Update(context.Background(), stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call(t, server, http.MethodPost, "/login/2fa", "", &payload.TwoFactorLoginRequest{
+		ChallengeToken: startTwoFactorLogin(t, server, "user@example.com"),
+		Code:           totpCode(t, secret, time.Now().Add(30*time.Second)),
+	}, http.StatusOK, nil)
+}
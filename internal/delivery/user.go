@@ -12,6 +12,13 @@ import (
 
 type userDelivery deliveryType
 
+func getClientInfo(c echo.Context) *payload.ClientInfo {
+	return &payload.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IP:        c.RealIP(),
+	}
+}
+
 func (d *userDelivery) RegisterUser(c echo.Context) error {
 	res := common.Response{}
 	req := &payload.RegisterUserRequest{}
@@ -19,17 +26,12 @@ func (d *userDelivery) RegisterUser(c echo.Context) error {
 	c.Bind(req)
 
 	if err := c.Validate(req); err != nil {
-		res.Error = utils.GetErrorValidation(err)
-		res.Status = false
-		res.Message = "Failed Registration"
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
-	registRes, err := d.Usecase.User.Register(req)
+	registRes, err := d.Usecase.User.Register(c.Request().Context(), req, getClientInfo(c))
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Registration"
@@ -46,21 +48,12 @@ func (d *userDelivery) LoginUser(c echo.Context) error {
 	c.Bind(req)
 
 	if err := c.Validate(req); err != nil {
-		res.Error = utils.GetErrorValidation(err)
-		res.Status = false
-		res.Message = "Failed Login"
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
-	registRes, err := d.Usecase.User.Login(req)
+	registRes, err := d.Usecase.User.Login(c.Request().Context(), req, getClientInfo(c))
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		if err.Error() == payload.ERROR_USER_NOT_FOUND {
-			return c.JSON(http.StatusUnauthorized, res)
-		} else {
-			return c.JSON(http.StatusPaymentRequired, res)
-		}
+		return err
 	}
 
 	res.Message = "Success Login"
@@ -70,16 +63,112 @@ func (d *userDelivery) LoginUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, res)
 }
 
+func (d *userDelivery) RefreshToken(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.RefreshTokenRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	tokenRes, err := d.Usecase.User.RefreshToken(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Refresh Token"
+	res.Data = tokenRes
+	res.Status = true
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) ForgotPassword(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.ForgotPasswordRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	err := d.Usecase.User.ForgotPassword(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "If the email has an account, a password reset link has been sent to it"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) ResetPassword(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.ResetPasswordRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	err := d.Usecase.User.ResetPassword(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Reset Password"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) VerifyEmail(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.VerifyEmailRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := d.Usecase.User.VerifyEmail(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Verify Email"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) ResendVerificationEmail(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.ResendVerificationEmail(c.Request().Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Resend Verification Email"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
 func (d *userDelivery) LogoutUser(c echo.Context) error {
 	res := common.Response{}
 
 	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+	sessionId := d.Middleware.JWT.GetSessionIdFromJwt(c)
 
-	err := d.Usecase.User.Logout(userId)
+	err := d.Usecase.User.Logout(c.Request().Context(), userId, sessionId)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Logout"
@@ -90,11 +179,9 @@ func (d *userDelivery) DeleteUser(c echo.Context) error {
 	res := common.Response{}
 	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
 
-	err := d.Usecase.User.DeleteAccount(userId)
+	err := d.Usecase.User.DeleteAccount(c.Request().Context(), userId)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Delete User"
@@ -108,19 +195,14 @@ func (d *userDelivery) UpdateUser(c echo.Context) error {
 	c.Bind(req)
 
 	if err := c.Validate(req); err != nil {
-		res.Error = utils.GetErrorValidation(err)
-		res.Status = false
-		res.Message = "Failed Update User"
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
 
-	err := d.Usecase.User.UpdateUser(userId, req)
+	err := d.Usecase.User.UpdateUser(c.Request().Context(), userId, req)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Status = true
@@ -132,14 +214,109 @@ func (d *userDelivery) GetUser(c echo.Context) error {
 	res := common.Response{}
 	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
 
-	user, err := d.Usecase.User.GetUser(userId)
+	user, err := d.Usecase.User.GetUser(c.Request().Context(), userId)
 	if err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, res)
+		return err
 	}
 
 	res.Message = "Success Get User"
 	res.Data = user
 	return c.JSON(http.StatusOK, res)
 }
+
+func (d *userDelivery) GetSessions(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+	sessionId := d.Middleware.JWT.GetSessionIdFromJwt(c)
+
+	sessions, err := d.Usecase.User.GetSessions(c.Request().Context(), userId, sessionId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Sessions"
+	res.Data = sessions
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) RevokeSession(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.RevokeSession(c.Request().Context(), userId, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Revoke Session"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+// log out from every device
+func (d *userDelivery) RevokeAllSessions(c echo.Context) error {
+	res := common.Response{}
+	userId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.LogoutAll(c.Request().Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Revoke All Sessions"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) GetUsers(c echo.Context) error {
+	res := common.Response{}
+
+	users, meta, err := d.Usecase.User.GetUsers(c.Request().Context(), utils.GetPagination(c))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Get Users"
+	res.Data = users
+	res.Meta = meta
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) UpdateUserRole(c echo.Context) error {
+	res := common.Response{}
+	req := &payload.UpdateUserRoleRequest{}
+
+	c.Bind(req)
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	actorId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	user, err := d.Usecase.User.UpdateUserRole(c.Request().Context(), actorId, c.Param("id"), req)
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Update User Role"
+	res.Data = user
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
+
+func (d *userDelivery) DeleteUserByID(c echo.Context) error {
+	res := common.Response{}
+	actorId := d.Middleware.JWT.GetUserIdFromJwt(c)
+
+	err := d.Usecase.User.DeleteUser(c.Request().Context(), actorId, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	res.Message = "Success Delete User"
+	res.Status = true
+	return c.JSON(http.StatusOK, res)
+}
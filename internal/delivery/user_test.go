@@ -0,0 +1,81 @@
+package delivery_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+func TestRegisterLoginRefresh(t *testing.T) {
+	runOnBackends(t, testRegisterLoginRefresh)
+}
+
+func testRegisterLoginRefresh(t *testing.T, server *apptest.Server) {
+	user := register(t, server, "User", "user@example.com")
+	if user.Token == "" || user.RefreshToken == "" || user.UserInfo.Email != "user@example.com" {
+		t.Fatalf("register = %+v, want tokens for user@example.com", user)
+	}
+
+	res := call(t, server, http.MethodPost, "/register", "", &payload.RegisterUserRequest{
+		Name:                 "Other",
+		Email:                "user@example.com",
+		Password:             "password",
+		PasswordConfirmation: "password",
+	}, http.StatusConflict, nil)
+	if res.Status {
+		t.Error("registering the email twice succeeded")
+	}
+
+	info := &payload.UserInfo{}
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusOK, info)
+	if info.ID != user.UserInfo.ID {
+		t.Errorf("GET /user = %s, want %s", info.ID, user.UserInfo.ID)
+	}
+
+	res = login(t, server, "user@example.com", "wrong-password", http.StatusUnauthorized)
+	if res.Code != payload.ErrInvalidCredentials.Code {
+		t.Errorf("wrong password code = %q, want %q", res.Code, payload.ErrInvalidCredentials.Code)
+	}
+
+	loggedIn := &payload.UserWithTokenResponse{}
+	call(t, server, http.MethodPost, "/login", "", &payload.LoginUserRequest{Email: "user@example.com", Password: "password"}, http.StatusOK, loggedIn)
+	if loggedIn.Token == "" || loggedIn.RefreshToken == "" {
+		t.Fatalf("login = %+v, want tokens", loggedIn)
+	}
+
+	refreshed := &payload.TokenResponse{}
+	call(t, server, http.MethodPost, "/token/refresh", "", &payload.RefreshTokenRequest{RefreshToken: loggedIn.RefreshToken}, http.StatusOK, refreshed)
+	if refreshed.RefreshToken == loggedIn.RefreshToken {
+		t.Error("the refresh token was not rotated")
+	}
+	call(t, server, http.MethodGet, "/user", refreshed.Token, nil, http.StatusOK, nil)
+
+	// logging out ends the session of the token only
+	call(t, server, http.MethodPost, "/logout", refreshed.Token, nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, "/user", refreshed.Token, nil, http.StatusUnauthorized, nil)
+	call(t, server, http.MethodGet, "/user", user.Token, nil, http.StatusOK, nil)
+
+	res = call(t, server, http.MethodPost, "/token/refresh", "", &payload.RefreshTokenRequest{RefreshToken: "unknown"}, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrTokenInvalid.Code {
+		t.Errorf("unknown refresh token code = %q, want %q", res.Code, payload.ErrTokenInvalid.Code)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	server := newServer(t)
+	user := register(t, server, "User", "user@example.com")
+
+	refreshed := &payload.TokenResponse{}
+	call(t, server, http.MethodPost, "/token/refresh", "", &payload.RefreshTokenRequest{RefreshToken: user.RefreshToken}, http.StatusOK, refreshed)
+
+	// the old refresh token is replayed, the whole session is revoked
+	res := call(t, server, http.MethodPost, "/token/refresh", "", &payload.RefreshTokenRequest{RefreshToken: user.RefreshToken}, http.StatusUnauthorized, nil)
+	if res.Code != payload.ErrRefreshTokenUsed.Code {
+		t.Errorf("reused refresh token code = %q, want %q", res.Code, payload.ErrRefreshTokenUsed.Code)
+	}
+
+	call(t, server, http.MethodPost, "/token/refresh", "", &payload.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}, http.StatusUnauthorized, nil)
+	call(t, server, http.MethodGet, "/user", refreshed.Token, nil, http.StatusUnauthorized, nil)
+}
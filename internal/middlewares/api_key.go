@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+const API_KEY_AUTH_SCHEME = "ApiKey"
+
+// apiKeyValidator returns the claims the request gets when the key is valid
+// and has the scope
+type apiKeyValidator func(c echo.Context, key string, scope string) (jwt.MapClaims, error)
+
+func newAPIKeyValidator(apiKeyRepo repository.IAPIKeyRepository, userRepo repository.IUserRepository) apiKeyValidator {
+	return func(c echo.Context, key string, scope string) (jwt.MapClaims, error) {
+		ctx := c.Request().Context()
+		apiKey, err := apiKeyRepo.SelectByHash(ctx, utils.HashToken(key))
+		if err != nil {
+			return nil, payload.ErrAPIKeyInvalid
+		}
+
+		user, err := userRepo.SelectByID(ctx, apiKey.UserID)
+		if err != nil || user.IsBanned() {
+			return nil, payload.ErrAPIKeyInvalid
+		}
+
+		if !apiKey.HasScope(scope) {
+			return nil, payload.ErrAPIKeyScope
+		}
+
+		if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > sessionTouchInterval {
+			apiKeyRepo.Touch(ctx, apiKey.ID, time.Now())
+		}
+
+		return jwt.MapClaims{
+			"sub":     user.ID,
+			"role":    user.Role,
+			"api_key": apiKey.ID,
+		}, nil
+	}
+}
+
+// accept an access token like ValidateJWT or an api key with the scope in the
+// "Authorization: ApiKey <key>" header, the key claims are put where the jwt
+// would be so the handlers read the user the same way
+func (m *CustomMiddleware) Authenticate(scope string) echo.MiddlewareFunc {
+	validateJWT := m.JWT.ValidateJWT()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := validateJWT(next)
+
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(auth, API_KEY_AUTH_SCHEME+" ") {
+				return withJWT(c)
+			}
+
+			claims, err := m.apiKeyValidator(c, strings.TrimPrefix(auth, API_KEY_AUTH_SCHEME+" "), scope)
+			if err != nil {
+				return err
+			}
+
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+			return next(c)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package middlewares
 
 import (
+	"time"
+
 	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/repository"
 	"github.com/haikalvidya/go-article/pkg/logger"
 	"github.com/haikalvidya/go-article/pkg/middleware"
 
@@ -9,16 +12,23 @@ import (
 )
 
 type jwtImpl interface {
-	GenerateToken(userId []byte) (string, error)
+	GenerateToken(userId []byte, sessionId string, role string) (string, error)
+	GenerateRefreshToken() (string, error)
+	GetRefreshTokenExpiration() time.Duration
 	ValidateJWT() echo.MiddlewareFunc
 	GetJWTClaims(c echo.Context) map[string]interface{}
 	GetUserIdFromJwt(c echo.Context) string
+	GetSessionIdFromJwt(c echo.Context) string
+	GetRoleFromJwt(c echo.Context) string
+	GetJWKS() *middleware.JWKS
 }
 
 type CustomMiddleware struct {
 	JWT            jwtImpl
 	Logger         logger.Logger
 	InternalAccess internalconnection
+
+	apiKeyValidator apiKeyValidator
 }
 
 type internalconnection interface {
@@ -29,11 +39,13 @@ type customMiddleware struct {
 	Config *config.Config
 }
 
-func New(cfg *config.Config) *CustomMiddleware {
+// jwtKeys is nil when the tokens are signed with the shared secret
+func New(cfg *config.Config, repo *repository.Repository, jwtKeys *middleware.JwtKeySet) *CustomMiddleware {
 
-	jwt := middleware.NewJwt(cfg.JWT.AccessTokenExpiredHour, cfg.JWT.Secret)
+	jwt := middleware.NewJwt(cfg.JWT.AccessTokenExpiredHour, cfg.JWT.RefreshTokenExpireHour, cfg.JWT.Secret, jwtKeys, sessionValidator(repo.Session))
 
 	logger := logger.NewApiLogger(cfg)
+	logger.InitLogger()
 
 	internalconnection := middleware.NewInternalAccess(cfg.Server.InternalAccessKey)
 
@@ -41,5 +53,7 @@ func New(cfg *config.Config) *CustomMiddleware {
 		JWT:            jwt,
 		Logger:         logger,
 		InternalAccess: internalconnection,
+
+		apiKeyValidator: newAPIKeyValidator(repo.APIKey, repo.User),
 	}
 }
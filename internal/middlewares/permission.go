@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/policy"
+
+	"github.com/labstack/echo/v4"
+)
+
+// reject the request when the role in the jwt does not have the permission,
+// it has to run after the jwt is validated
+func (m *CustomMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !policy.HasPermission(m.JWT.GetRoleFromJwt(c), permission) {
+				return payload.ErrPermissionDenied
+			}
+			return next(c)
+		}
+	}
+}
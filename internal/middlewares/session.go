@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// last seen is only written when it is older than this, to avoid a redis write on every request
+const sessionTouchInterval = time.Minute
+
+func sessionValidator(sessionRepo repository.ISessionRepository) middleware.SessionValidator {
+	return func(c echo.Context, userId string, sessionId string) error {
+		if sessionId == "" {
+			return payload.ErrSessionNotFound
+		}
+
+		ctx := c.Request().Context()
+		session, err := sessionRepo.SelectByID(ctx, sessionId)
+		if err != nil || session.UserID != userId {
+			return payload.ErrSessionNotFound
+		}
+
+		if time.Since(session.LastSeenAt) > sessionTouchInterval || session.IP != c.RealIP() {
+			session.LastSeenAt = time.Now()
+			session.IP = c.RealIP()
+			sessionRepo.Touch(ctx, session)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	API_KEY_SCOPE_READ           = "read"
+	API_KEY_SCOPE_WRITE_ARTICLES = "write:articles"
+)
+
+// APIKeyModel lets scripts call the api as the user, only the hash of the key
+// is stored, the prefix is kept so the user can tell the keys apart
+type APIKeyModel struct {
+	ID      string `db:"id"`
+	UserID  string `db:"user_id"`
+	Name    string `db:"name"`
+	Prefix  string `db:"prefix"`
+	KeyHash string `db:"key_hash"`
+	// comma separated
+	Scopes     string     `db:"scopes"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (APIKeyModel) TableName() string {
+	return "user_api_keys"
+}
+
+func (k *APIKeyModel) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.New().String()
+	k.CreatedAt = time.Now()
+	return
+}
+
+func (k *APIKeyModel) ScopeList() []string {
+	return strings.Split(k.Scopes, ",")
+}
+
+func (k *APIKeyModel) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKeyModel) Info() *payload.APIKeyInfo {
+	res := &payload.APIKeyInfo{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.ScopeList(),
+		CreatedAt: k.CreatedAt.Format(time.RFC3339),
+	}
+	if k.LastUsedAt != nil {
+		res.LastUsedAt = k.LastUsedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -7,16 +7,41 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ARTICLE_STATUS_DRAFT     = "draft"
+	ARTICLE_STATUS_SCHEDULED = "scheduled"
+	ARTICLE_STATUS_PUBLISHED = "published"
+	ARTICLE_STATUS_ARCHIVED  = "archived"
+	// set by the internal api, the author can not change the status back
+	ARTICLE_STATUS_TAKEN_DOWN = "taken_down"
+)
+
+// allowed status changes, draft -> (scheduled ->) published -> archived and unpublish back to draft
+var articleStatusTransitions = map[string][]string{
+	ARTICLE_STATUS_DRAFT:      {ARTICLE_STATUS_SCHEDULED, ARTICLE_STATUS_PUBLISHED},
+	ARTICLE_STATUS_SCHEDULED:  {ARTICLE_STATUS_DRAFT, ARTICLE_STATUS_SCHEDULED, ARTICLE_STATUS_PUBLISHED},
+	ARTICLE_STATUS_PUBLISHED:  {ARTICLE_STATUS_DRAFT, ARTICLE_STATUS_ARCHIVED},
+	ARTICLE_STATUS_ARCHIVED:   {ARTICLE_STATUS_DRAFT},
+	ARTICLE_STATUS_TAKEN_DOWN: {},
+}
+
 type ArticleModel struct {
-	ID        int            `db:"id"`
-	Title     string         `db:"title"`
-	Body      string         `db:"body"`
-	AuthorID  string         `db:"author_id"`
-	CreatedAt time.Time      `db:"created_at"`
-	UpdatedAt *time.Time     `db:"updated_at"`
-	DeletedAt gorm.DeletedAt `db:"deleted_at"`
+	ID          int            `db:"id"`
+	Title       string         `db:"title"`
+	Body        string         `db:"body"`
+	Status      string         `db:"status"`
+	PublishedAt *time.Time     `db:"published_at"`
+	PublishAt   *time.Time     `db:"publish_at"`
+	AuthorID    string         `db:"author_id"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   *time.Time     `db:"updated_at"`
+	DeletedAt   gorm.DeletedAt `db:"deleted_at"`
 
-	Author *UserModel `gorm:"foreignKey:AuthorID"`
+	// only filled when the article is selected with its comment count
+	CommentCount int64 `db:"comment_count" gorm:"->;-:migration"`
+
+	Author *UserModel  `gorm:"foreignKey:AuthorID"`
+	Tags   []*TagModel `gorm:"many2many:article_tags;joinForeignKey:ArticleID;joinReferences:TagID"`
 }
 
 func (ArticleModel) TableName() string {
@@ -25,22 +50,94 @@ func (ArticleModel) TableName() string {
 
 func (a *ArticleModel) BeforeCreate(tx *gorm.DB) (err error) {
 	a.CreatedAt = time.Now()
+	if a.Status == "" {
+		a.Status = ARTICLE_STATUS_DRAFT
+	}
+	if a.Status == ARTICLE_STATUS_PUBLISHED && a.PublishedAt == nil {
+		a.PublishedAt = &a.CreatedAt
+	}
 	return
 }
 
+func (a *ArticleModel) IsPublished() bool {
+	return a.Status == ARTICLE_STATUS_PUBLISHED
+}
+
+func (a *ArticleModel) CanTransitionTo(status string) bool {
+	for _, next := range articleStatusTransitions[a.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// change the status of the article following the lifecycle
+func (a *ArticleModel) TransitionTo(status string) error {
+	if !a.CanTransitionTo(status) {
+		return payload.ErrArticleStatusTransition
+	}
+
+	a.Status = status
+	if status == ARTICLE_STATUS_PUBLISHED {
+		now := time.Now()
+		a.PublishedAt = &now
+	}
+	if status != ARTICLE_STATUS_SCHEDULED {
+		a.PublishAt = nil
+	}
+	return nil
+}
+
+// take the article down from any status, bypassing the lifecycle
+func (a *ArticleModel) TakeDown() {
+	a.Status = ARTICLE_STATUS_TAKEN_DOWN
+	a.PublishAt = nil
+}
+
+// schedule the article to be published by the worker at the given time
+func (a *ArticleModel) Schedule(publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return payload.ErrArticlePublishAtPast
+	}
+
+	err := a.TransitionTo(ARTICLE_STATUS_SCHEDULED)
+	if err != nil {
+		return err
+	}
+
+	a.PublishAt = &publishAt
+	return nil
+}
+
 func (a *ArticleModel) PublicInfo() *payload.ArticleInfo {
 	res := &payload.ArticleInfo{
-		ID:        a.ID,
-		Title:     a.Title,
-		Content:   a.Body,
-		AuthorID:  a.AuthorID,
-		CreatedAt: a.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: a.UpdatedAt.Format(time.RFC3339),
+		ID:           a.ID,
+		Title:        a.Title,
+		Content:      a.Body,
+		Status:       a.Status,
+		CommentCount: a.CommentCount,
+		AuthorID:     a.AuthorID,
+		CreatedAt:    a.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    a.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if a.PublishedAt != nil {
+		res.PublishedAt = a.PublishedAt.Format(time.RFC3339)
+	}
+
+	if a.PublishAt != nil {
+		res.PublishAt = a.PublishAt.Format(time.RFC3339)
 	}
 
 	if a.Author != nil {
 		res.Author = a.Author.PublicInfo()
 	}
 
+	res.Tags = make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		res.Tags = append(res.Tags, tag.Name)
+	}
+
 	return res
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"gorm.io/gorm"
+)
+
+type ArticleRevisionModel struct {
+	ID           int       `db:"id"`
+	ArticleID    int       `db:"article_id"`
+	Revision     int       `db:"revision"`
+	Title        string    `db:"title"`
+	Body         string    `db:"body"`
+	EditorID     string    `db:"editor_id"`
+	RestoredFrom *int      `db:"restored_from"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func (ArticleRevisionModel) TableName() string {
+	return "article_revisions"
+}
+
+func (r *ArticleRevisionModel) BeforeCreate(tx *gorm.DB) (err error) {
+	r.CreatedAt = time.Now()
+	return
+}
+
+func (r *ArticleRevisionModel) PublicInfo() *payload.ArticleRevisionInfo {
+	return &payload.ArticleRevisionInfo{
+		ArticleID:    r.ArticleID,
+		Revision:     r.Revision,
+		Title:        r.Title,
+		Content:      r.Body,
+		EditorID:     r.EditorID,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"gorm.io/gorm"
+)
+
+type CommentModel struct {
+	ID        int            `db:"id"`
+	ArticleID int            `db:"article_id"`
+	AuthorID  string         `db:"author_id"`
+	ParentID  *int           `db:"parent_id"`
+	Body      string         `db:"body"`
+	HiddenAt  *time.Time     `db:"hidden_at"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt *time.Time     `db:"updated_at"`
+	DeletedAt gorm.DeletedAt `db:"deleted_at"`
+
+	Author *UserModel `gorm:"foreignKey:AuthorID"`
+}
+
+func (CommentModel) TableName() string {
+	return "comments"
+}
+
+func (c *CommentModel) BeforeCreate(tx *gorm.DB) (err error) {
+	c.CreatedAt = time.Now()
+	return
+}
+
+func (c *CommentModel) IsHidden() bool {
+	return c.HiddenAt != nil
+}
+
+func (c *CommentModel) IsDeleted() bool {
+	return c.DeletedAt.Valid
+}
+
+// hidden and deleted comments keep their place in the thread but lose their content
+func (c *CommentModel) PublicInfo() *payload.CommentInfo {
+	res := &payload.CommentInfo{
+		ID:        c.ID,
+		ArticleID: c.ArticleID,
+		ParentID:  c.ParentID,
+		Hidden:    c.IsHidden(),
+		Deleted:   c.IsDeleted(),
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		Replies:   []*payload.CommentInfo{},
+	}
+
+	if c.UpdatedAt != nil {
+		res.UpdatedAt = c.UpdatedAt.Format(time.RFC3339)
+	}
+
+	if res.Hidden || res.Deleted {
+		return res
+	}
+
+	res.Content = c.Body
+	res.AuthorID = c.AuthorID
+	if c.Author != nil {
+		res.Author = c.Author.PublicInfo()
+	}
+
+	return res
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeModel is a single use code to log in when the authenticator is lost,
+// only the hash of the code is stored
+type RecoveryCodeModel struct {
+	ID        int        `db:"id"`
+	UserID    string     `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func (RecoveryCodeModel) TableName() string {
+	return "user_recovery_codes"
+}
+
+func (c *RecoveryCodeModel) BeforeCreate(tx *gorm.DB) (err error) {
+	c.CreatedAt = time.Now()
+	return
+}
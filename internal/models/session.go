@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+// SessionModel is a single logged in device, it is stored in redis instead of the database
+type SessionModel struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+func (s *SessionModel) PublicInfo(currentSessionID string) *payload.SessionInfo {
+	return &payload.SessionInfo{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+		LastSeenAt: s.LastSeenAt.Format(time.RFC3339),
+		Current:    s.ID == currentSessionID,
+	}
+}
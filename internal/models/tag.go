@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"gorm.io/gorm"
+)
+
+type TagModel struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// only filled when the tags are listed with their usage
+	ArticleCount int64 `db:"article_count" gorm:"->;-:migration"`
+}
+
+func (TagModel) TableName() string {
+	return "tags"
+}
+
+func (t *TagModel) BeforeCreate(tx *gorm.DB) (err error) {
+	t.CreatedAt = time.Now()
+	return
+}
+
+func (t *TagModel) PublicInfo() *payload.TagInfo {
+	return &payload.TagInfo{
+		Name:         t.Name,
+		ArticleCount: t.ArticleCount,
+	}
+}
+
+// tags are compared lowercased and without surrounding spaces, duplicates are removed
+func NormalizeTagNames(names []string) []string {
+	res := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	return res
+}
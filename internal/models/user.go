@@ -9,20 +9,40 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	USER_ROLE_READER = "reader"
+	USER_ROLE_AUTHOR = "author"
+	USER_ROLE_EDITOR = "editor"
+	USER_ROLE_ADMIN  = "admin"
+)
+
+// new users can write articles like before roles existed
+const DEFAULT_USER_ROLE = USER_ROLE_AUTHOR
+
 type UserModel struct {
-	ID        string         `db:"id"`
-	Email     string         `db:"email"`
-	Name      string         `db:"name"`
-	Password  string         `db:"password"`
-	CreatedAt time.Time      `db:"created_at"`
-	UpdatedAt *time.Time     `db:"updated_at"`
-	DeletedAt gorm.DeletedAt `db:"deleted_at"`
+	ID    string `db:"id"`
+	Email string `db:"email"`
+	// nil until the user opens the link sent to the email
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	Name            string     `db:"name"`
+	Password        string     `db:"password"`
+	// set on enrollment, two factor is only on once the enrollment is confirmed
+	TotpSecret    *string        `db:"totp_secret"`
+	TotpEnabledAt *time.Time     `db:"totp_enabled_at"`
+	Role          string         `db:"role"`
+	BannedAt      *time.Time     `db:"banned_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     *time.Time     `db:"updated_at"`
+	DeletedAt     gorm.DeletedAt `db:"deleted_at"`
 }
 
 // create before create gorm for adding uuid to id and created_at time
 func (u *UserModel) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New().String()
 	u.CreatedAt = time.Now()
+	if u.Role == "" {
+		u.Role = DEFAULT_USER_ROLE
+	}
 	return
 }
 
@@ -31,11 +51,48 @@ func (u *UserModel) PublicInfo() *payload.UserInfo {
 		ID:    u.ID,
 		Name:  u.Name,
 		Email: u.Email,
+		Role:  u.Role,
+
+		EmailVerified:    u.IsEmailVerified(),
+		TwoFactorEnabled: u.IsTwoFactorEnabled(),
 	}
 
 	return userpayload
 }
 
+func (u *UserModel) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *UserModel) IsTwoFactorEnabled() bool {
+	return u.TotpEnabledAt != nil
+}
+
+func (u *UserModel) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+func (u *UserModel) InternalInfo(sessionCount int) *payload.InternalUserInfo {
+	res := &payload.InternalUserInfo{
+		ID:           u.ID,
+		Name:         u.Name,
+		Email:        u.Email,
+		Role:         u.Role,
+		CreatedAt:    u.CreatedAt.Format(time.RFC3339),
+		SessionCount: sessionCount,
+	}
+
+	if u.EmailVerifiedAt != nil {
+		res.EmailVerifiedAt = u.EmailVerifiedAt.Format(time.RFC3339)
+	}
+
+	if u.BannedAt != nil {
+		res.BannedAt = u.BannedAt.Format(time.RFC3339)
+	}
+
+	return res
+}
+
 func (UserModel) TableName() string {
 	return "users"
 }
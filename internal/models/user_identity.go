@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityModel links an account of an openid connect provider to a user,
+// the issuer and subject together name the account
+type UserIdentityModel struct {
+	ID      int    `db:"id"`
+	UserID  string `db:"user_id"`
+	Issuer  string `db:"issuer"`
+	Subject string `db:"subject"`
+	// the email the provider gave when the identity was linked
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (UserIdentityModel) TableName() string {
+	return "user_identities"
+}
+
+func (i *UserIdentityModel) BeforeCreate(tx *gorm.DB) (err error) {
+	i.CreatedAt = time.Now()
+	return
+}
+
+// OIDCLoginModel is kept in redis between sending the user to the provider and
+// the user coming back with the code
+type OIDCLoginModel struct {
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
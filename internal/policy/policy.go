@@ -0,0 +1,94 @@
+package policy
+
+import "github.com/haikalvidya/go-article/internal/models"
+
+const (
+	// write and edit your own articles
+	PERMISSION_ARTICLE_WRITE = "article:write"
+	// edit, delete and change the status of any article
+	PERMISSION_ARTICLE_MANAGE = "article:manage"
+	// hide and delete any comment
+	PERMISSION_COMMENT_MODERATE = "comment:moderate"
+	// list and delete users with a lower role
+	PERMISSION_USER_MANAGE = "user:manage"
+	// change the role of users
+	PERMISSION_USER_ASSIGN_ROLE = "user:assign_role"
+)
+
+var rolePermissions = map[string][]string{
+	models.USER_ROLE_READER: {},
+	models.USER_ROLE_AUTHOR: {PERMISSION_ARTICLE_WRITE},
+	models.USER_ROLE_EDITOR: {PERMISSION_ARTICLE_WRITE, PERMISSION_ARTICLE_MANAGE, PERMISSION_COMMENT_MODERATE, PERMISSION_USER_MANAGE},
+	models.USER_ROLE_ADMIN:  {PERMISSION_ARTICLE_WRITE, PERMISSION_ARTICLE_MANAGE, PERMISSION_COMMENT_MODERATE, PERMISSION_USER_MANAGE, PERMISSION_USER_ASSIGN_ROLE},
+}
+
+// a user can only manage users ranked below them
+var roleRanks = map[string]int{
+	models.USER_ROLE_READER: 1,
+	models.USER_ROLE_AUTHOR: 2,
+	models.USER_ROLE_EDITOR: 3,
+	models.USER_ROLE_ADMIN:  4,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role string, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func CanWriteArticle(user *models.UserModel) bool {
+	return HasPermission(user.Role, PERMISSION_ARTICLE_WRITE)
+}
+
+// the author can edit their own article as long as they may still write articles
+func CanEditArticle(user *models.UserModel, article *models.ArticleModel) bool {
+	if HasPermission(user.Role, PERMISSION_ARTICLE_MANAGE) {
+		return true
+	}
+	return article.AuthorID == user.ID && CanWriteArticle(user)
+}
+
+func CanDeleteArticle(user *models.UserModel, article *models.ArticleModel) bool {
+	return CanEditArticle(user, article)
+}
+
+// drafts and revisions are only visible to the people who can edit the article
+func CanViewUnpublishedArticle(user *models.UserModel, article *models.ArticleModel) bool {
+	return article.AuthorID == user.ID || HasPermission(user.Role, PERMISSION_ARTICLE_MANAGE)
+}
+
+func CanEditComment(user *models.UserModel, comment *models.CommentModel) bool {
+	return comment.AuthorID == user.ID
+}
+
+func CanDeleteComment(user *models.UserModel, comment *models.CommentModel) bool {
+	return comment.AuthorID == user.ID || HasPermission(user.Role, PERMISSION_COMMENT_MODERATE)
+}
+
+// the author of the article moderates the comments on it
+func CanModerateComments(user *models.UserModel, article *models.ArticleModel) bool {
+	return article.AuthorID == user.ID || HasPermission(user.Role, PERMISSION_COMMENT_MODERATE)
+}
+
+func CanManageUser(user *models.UserModel, target *models.UserModel) bool {
+	if !HasPermission(user.Role, PERMISSION_USER_MANAGE) {
+		return false
+	}
+	return user.ID != target.ID && roleRanks[user.Role] > roleRanks[target.Role]
+}
+
+// roles up to your own can be given, so an admin can make other admins
+func CanAssignRole(user *models.UserModel, target *models.UserModel, role string) bool {
+	if !HasPermission(user.Role, PERMISSION_USER_ASSIGN_ROLE) || !IsValidRole(role) {
+		return false
+	}
+	return CanManageUser(user, target) && roleRanks[role] <= roleRanks[user.Role]
+}
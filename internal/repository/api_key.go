@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKeyModel) error
+	SelectByUserID(ctx context.Context, userID string) ([]*models.APIKeyModel, error)
+	SelectByHash(ctx context.Context, keyHash string) (*models.APIKeyModel, error)
+	CountByUserID(ctx context.Context, userID string) (int64, error)
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+	Delete(ctx context.Context, id string, userID string) (bool, error)
+}
+
+type apiKeyRepository repositoryType
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKeyModel) error {
+	return getDB(ctx, r.DB).Create(key).Error
+}
+
+func (r *apiKeyRepository) SelectByUserID(ctx context.Context, userID string) ([]*models.APIKeyModel, error) {
+	keys := []*models.APIKeyModel{}
+	err := getDB(ctx, r.DB).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) SelectByHash(ctx context.Context, keyHash string) (*models.APIKeyModel, error) {
+	key := &models.APIKeyModel{}
+	err := getDB(ctx, r.DB).Where("key_hash = ?", keyHash).First(key).Error
+	if err != nil {
+		return nil, translateError(err, payload.ErrAPIKeyInvalid)
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := getDB(ctx, r.DB).Model(&models.APIKeyModel{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	return getDB(ctx, r.DB).Model(&models.APIKeyModel{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+// returns false when the user has no key with the id
+func (r *apiKeyRepository) Delete(ctx context.Context, id string, userID string) (bool, error) {
+	res := getDB(ctx, r.DB).Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKeyModel{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
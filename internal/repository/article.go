@@ -1,88 +1,310 @@
 package repository
 
 import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
 	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/common"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IArticleRepository interface {
-	GetAll() ([]*models.ArticleModel, error)
-	SelectByID(id int) (*models.ArticleModel, error)
-	SelectByAuthorID(authorID string) ([]*models.ArticleModel, error)
-	SearchByTitleAndContent(content string) ([]*models.ArticleModel, error)
-	SearchByTitleAndContentAndAuthorID(authorID string, content string) ([]*models.ArticleModel, error)
-	CreateTx(tx *gorm.DB, article *models.ArticleModel) (*models.ArticleModel, error)
-	DeleteTx(tx *gorm.DB, article *models.ArticleModel) error
-	UpdateTx(tx *gorm.DB, article *models.ArticleModel) error
+	GetAll(ctx context.Context, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)
+	SelectByID(ctx context.Context, id int) (*models.ArticleModel, error)
+	SelectByAuthorID(ctx context.Context, authorID string, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)
+	SelectByAuthorIDAndStatus(ctx context.Context, authorID string, status string, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)
+	Create(ctx context.Context, article *models.ArticleModel) (*models.ArticleModel, error)
+	Delete(ctx context.Context, article *models.ArticleModel) error
+	Update(ctx context.Context, article *models.ArticleModel) error
+	ReplaceTags(ctx context.Context, article *models.ArticleModel, tags []*models.TagModel) error
+	PublishScheduled(ctx context.Context, now time.Time, limit int) ([]*models.ArticleModel, error)
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// ArticleFilter narrows down and orders the article listings
+type ArticleFilter struct {
+	Tags         []string
+	MatchAllTags bool
+	// column to sort by, prefixed with - for descending
+	Sort string
+	// position to continue from on cursor pagination, nil starts from the first page
+	Cursor *ArticleCursor
+	// only list these articles, in this order unless another sort is asked for,
+	// used for the hits of a search
+	IDs []int
+}
+
+// ArticleCursor is the created_at and id of the last article of a keyset page
+type ArticleCursor struct {
+	CreatedAt time.Time
+	ID        int
+	// load the page before the position instead of the one after it
+	Before bool
+}
+
+const DEFAULT_ARTICLE_SORT = "-created_at"
+
+var articleSortColumns = map[string]string{
+	"created_at": "articles.created_at",
+	"updated_at": "articles.updated_at",
+	"title":      "articles.title",
 }
 
 type articleRepository repositoryType
 
-func (r *articleRepository) GetAll() ([]*models.ArticleModel, error) {
+// scope adding the number of visible comments of every article
+func withCommentCount(db *gorm.DB) *gorm.DB {
+	return db.Select("articles.*, (SELECT COUNT(*) FROM comments WHERE comments.article_id = articles.id AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL) AS comment_count")
+}
+
+// scope applying the filter to an article query
+func (r *articleRepository) filter(filter *ArticleFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter == nil {
+			return db
+		}
+
+		if filter.IDs != nil {
+			db = db.Where("articles.id IN ?", filter.IDs)
+		}
+
+		if len(filter.Tags) == 0 {
+			return db
+		}
+
+		tagged := r.DB.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags).
+			Group("article_tags.article_id")
+		if filter.MatchAllTags {
+			tagged = tagged.Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+
+		return db.Where("articles.id IN (?)", tagged)
+	}
+}
+
+func sortArticles(filter *ArticleFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter != nil && len(filter.IDs) > 0 && filter.Sort == "" {
+			// CASE instead of FIELD, which only mysql has. the positions are written
+			// out because postgres would compare them as text when they are params
+			sql := "CASE articles.id"
+			vars := make([]interface{}, 0, len(filter.IDs))
+			for i, id := range filter.IDs {
+				sql += " WHEN ? THEN " + strconv.Itoa(i)
+				vars = append(vars, id)
+			}
+			return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:                sql + " END",
+				Vars:               vars,
+				WithoutParentheses: true,
+			}})
+		}
+
+		sort := DEFAULT_ARTICLE_SORT
+		if filter != nil && filter.Sort != "" {
+			sort = filter.Sort
+		}
+
+		desc := strings.HasPrefix(sort, "-")
+		column, ok := articleSortColumns[strings.TrimPrefix(sort, "-")]
+		if !ok {
+			column, desc = articleSortColumns["created_at"], true
+		}
+
+		// id keeps the order stable between pages when the column has equal values
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}, Desc: desc}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "articles.id", Raw: true}, Desc: desc})
+	}
+}
+
+func paginate(pagination *common.PaginationRequest) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if pagination == nil {
+			return db
+		}
+		return db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+}
+
+// scope continuing the listing from the cursor, only created_at can be sorted on
+// so the rows can be found by the index instead of skipping over an offset
+func keyset(filter *ArticleFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		desc := filter == nil || filter.Sort != "created_at"
+		if filter != nil && filter.Cursor != nil && filter.Cursor.Before {
+			desc = !desc
+		}
+
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "articles.created_at", Raw: true}, Desc: desc}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "articles.id", Raw: true}, Desc: desc})
+
+		if filter == nil || filter.Cursor == nil {
+			return db
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		return db.Where("(articles.created_at "+op+" ? OR (articles.created_at = ? AND articles.id "+op+" ?))",
+			filter.Cursor.CreatedAt, filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+}
+
+// count the matching articles then load the requested page of them
+func (r *articleRepository) list(query *gorm.DB, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	query = query.Model(&models.ArticleModel{}).Scopes(r.filter(filter)).Session(&gorm.Session{})
+
+	// keyset pages are not counted, counting would scan the whole listing again
+	if pagination != nil && pagination.CursorMode {
+		articles := []*models.ArticleModel{}
+		err := query.Preload("Author").Preload("Tags").
+			Scopes(withCommentCount, keyset(filter)).
+			Limit(int(pagination.Limit)).
+			Find(&articles).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		return articles, 0, nil
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
 	articles := []*models.ArticleModel{}
-	err := r.DB.Preload("Author").Find(&articles).Error
+	err = query.Preload("Author").Preload("Tags").
+		Scopes(withCommentCount, sortArticles(filter), paginate(pagination)).
+		Find(&articles).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return articles, nil
+	return articles, total, nil
+}
+
+func (r *articleRepository) GetAll(ctx context.Context, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	return r.list(getDB(ctx, r.DB).Where("status = ?", models.ARTICLE_STATUS_PUBLISHED), filter, pagination)
 }
 
-func (r *articleRepository) SelectByID(id int) (*models.ArticleModel, error) {
+func (r *articleRepository) SelectByID(ctx context.Context, id int) (*models.ArticleModel, error) {
 	article := &models.ArticleModel{}
-	err := r.DB.Preload("Author").Where("id = ?", id).First(article).Error
+	err := getDB(ctx, r.DB).Preload("Author").Preload("Tags").Scopes(withCommentCount).Where("id = ?", id).First(article).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, payload.ErrArticleNotFound)
 	}
 	return article, nil
 }
 
-func (r *articleRepository) SelectByAuthorID(authorID string) ([]*models.ArticleModel, error) {
-	articles := []*models.ArticleModel{}
-	err := r.DB.Preload("Author").Where("author_id = ?", authorID).Find(&articles).Error
-	if err != nil {
-		return nil, err
+func (r *articleRepository) SelectByAuthorID(ctx context.Context, authorID string, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	return r.list(getDB(ctx, r.DB).Where("author_id = ? AND status = ?", authorID, models.ARTICLE_STATUS_PUBLISHED), filter, pagination)
+}
+
+// list articles of the author in any status, empty status means all of them
+func (r *articleRepository) SelectByAuthorIDAndStatus(ctx context.Context, authorID string, status string, filter *ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	query := getDB(ctx, r.DB).Where("author_id = ?", authorID)
+	if status != "" {
+		query = query.Where("status = ?", status)
 	}
-	return articles, nil
+	return r.list(query, filter, pagination)
 }
 
-func (r *articleRepository) SearchByTitleAndContent(content string) ([]*models.ArticleModel, error) {
-	articles := []*models.ArticleModel{}
-	err := r.DB.Preload("Author").Where("title LIKE ? OR body LIKE ?", "%"+content+"%", "%"+content+"%").Find(&articles).Error
+func (r *articleRepository) Create(ctx context.Context, article *models.ArticleModel) (*models.ArticleModel, error) {
+	err := getDB(ctx, r.DB).Omit(clause.Associations).Create(article).Error
 	if err != nil {
 		return nil, err
 	}
-	return articles, nil
+	return article, nil
 }
 
-func (r *articleRepository) CreateTx(tx *gorm.DB, article *models.ArticleModel) (*models.ArticleModel, error) {
-	err := tx.Create(article).Error
+func (r *articleRepository) Delete(ctx context.Context, article *models.ArticleModel) error {
+	err := getDB(ctx, r.DB).Delete(&article).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return article, nil
+	return nil
 }
 
-func (r *articleRepository) DeleteTx(tx *gorm.DB, article *models.ArticleModel) error {
-	err := tx.Delete(&article).Error
+func (r *articleRepository) Update(ctx context.Context, article *models.ArticleModel) error {
+	err := getDB(ctx, r.DB).Omit(clause.Associations).Save(&article).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *articleRepository) UpdateTx(tx *gorm.DB, article *models.ArticleModel) error {
-	err := tx.Save(&article).Error
+func (r *articleRepository) ReplaceTags(ctx context.Context, article *models.ArticleModel, tags []*models.TagModel) error {
+	err := getDB(ctx, r.DB).Model(article).Omit("Tags.*").Association("Tags").Replace(tags)
 	if err != nil {
 		return err
 	}
+	article.Tags = tags
 	return nil
 }
 
-func (r *articleRepository) SearchByTitleAndContentAndAuthorID(authorID string, content string) ([]*models.ArticleModel, error) {
+// claim the scheduled articles that are due and publish them, rows locked by
+// another worker are skipped so several workers can run at the same time. the
+// rows are only locked when it is called inside a transaction
+func (r *articleRepository) PublishScheduled(ctx context.Context, now time.Time, limit int) ([]*models.ArticleModel, error) {
+	db := getDB(ctx, r.DB)
 	articles := []*models.ArticleModel{}
-	err := r.DB.Preload("Author").Where("author_id = ? AND (title LIKE ? OR body LIKE ?)", authorID, "%"+content+"%", "%"+content+"%").Find(&articles).Error
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND publish_at <= ?", models.ARTICLE_STATUS_SCHEDULED, now).
+		Order("publish_at").
+		Limit(limit).
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(articles) == 0 {
+		return articles, nil
+	}
+
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+
+	err = db.Model(&models.ArticleModel{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":       models.ARTICLE_STATUS_PUBLISHED,
+		"published_at": gorm.Expr("publish_at"),
+		"publish_at":   nil,
+	}).Error
 	if err != nil {
 		return nil, err
 	}
+
+	for _, article := range articles {
+		article.Status = models.ARTICLE_STATUS_PUBLISHED
+		article.PublishedAt = article.PublishAt
+		article.PublishAt = nil
+	}
 	return articles, nil
 }
+
+func (r *articleRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	rows := []struct {
+		Status string
+		Total  int64
+	}{}
+	err := getDB(ctx, r.DB).Model(&models.ArticleModel{}).Select("status, COUNT(*) AS total").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]int64{}
+	for _, row := range rows {
+		res[row.Status] = row.Total
+	}
+	return res, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type IArticleRevisionRepository interface {
+	SelectByArticleID(ctx context.Context, articleID int) ([]*models.ArticleRevisionModel, error)
+	SelectByArticleIDAndRevision(ctx context.Context, articleID int, revision int) (*models.ArticleRevisionModel, error)
+	Create(ctx context.Context, revision *models.ArticleRevisionModel) (*models.ArticleRevisionModel, error)
+}
+
+type articleRevisionRepository repositoryType
+
+func (r *articleRevisionRepository) SelectByArticleID(ctx context.Context, articleID int) ([]*models.ArticleRevisionModel, error) {
+	revisions := []*models.ArticleRevisionModel{}
+	err := getDB(ctx, r.DB).Where("article_id = ?", articleID).Order("revision DESC").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *articleRevisionRepository) SelectByArticleIDAndRevision(ctx context.Context, articleID int, revision int) (*models.ArticleRevisionModel, error) {
+	articleRevision := &models.ArticleRevisionModel{}
+	err := getDB(ctx, r.DB).Where("article_id = ? AND revision = ?", articleID, revision).First(articleRevision).Error
+	if err != nil {
+		return nil, translateError(err, payload.ErrRevisionNotFound)
+	}
+	return articleRevision, nil
+}
+
+// create the next revision of the article. the article row is locked so
+// concurrent edits of one article take their revision numbers one after the
+// other, the lock only holds when it is called inside a transaction
+func (r *articleRevisionRepository) Create(ctx context.Context, revision *models.ArticleRevisionModel) (*models.ArticleRevisionModel, error) {
+	db := getDB(ctx, r.DB)
+	var articleID int
+	err := db.Model(&models.ArticleModel{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", revision.ArticleID).
+		Scan(&articleID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// a locking read sees the revisions committed before the lock was taken,
+	// a plain read in mysql could still see an older snapshot
+	latest := []int{}
+	err = db.Model(&models.ArticleRevisionModel{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("article_id = ?", revision.ArticleID).
+		Order("revision DESC").
+		Limit(1).
+		Pluck("revision", &latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revision.Revision = 1
+	if len(latest) > 0 {
+		revision.Revision = latest[0] + 1
+	}
+
+	err = db.Create(revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
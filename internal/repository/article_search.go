@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/haikalvidya/go-article/config"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/search"
+
+	"gorm.io/gorm"
+)
+
+// articleSearchIndex searches with the fulltext index of the articles table, the
+// database keeps that index up to date so indexing and rebuilding do nothing.
+// sqlite has no such index, the articles are matched with LIKE there and can not
+// be ranked so the newest come first
+type articleSearchIndex repositoryType
+
+// the document postgres searches, the expression has to match the one of the index
+const postgresArticleDocument = "to_tsvector('simple', title || ' ' || body)"
+
+func NewArticleSearchIndex(db *gorm.DB) search.SearchIndex {
+	return &articleSearchIndex{DB: db}
+}
+
+func (r *articleSearchIndex) Index(ctx context.Context, doc *search.Document) error {
+	return nil
+}
+
+func (r *articleSearchIndex) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *articleSearchIndex) Rebuild(ctx context.Context, docs []*search.Document) error {
+	return nil
+}
+
+func (r *articleSearchIndex) Search(ctx context.Context, query *search.Query, limit int) ([]*search.Hit, error) {
+	db := getDB(ctx, r.DB).Model(&models.ArticleModel{}).Where("status = ?", models.ARTICLE_STATUS_PUBLISHED)
+	order := "score DESC, id DESC"
+
+	switch r.DB.Dialector.Name() {
+	case config.DRIVER_POSTGRES:
+		tsQuery := query.TSQuery()
+		db = db.Select("id, ts_rank("+postgresArticleDocument+", to_tsquery('simple', ?)) AS score", tsQuery).
+			Where(postgresArticleDocument+" @@ to_tsquery('simple', ?)", tsQuery)
+	case config.DRIVER_SQLITE:
+		db = db.Select("id, 0 AS score").Scopes(matchLike(query))
+		order = "published_at DESC, id DESC"
+	default:
+		booleanMode := query.BooleanMode()
+		db = db.Select("id, MATCH(title, body) AGAINST (? IN BOOLEAN MODE) AS score", booleanMode).
+			Where("MATCH(title, body) AGAINST (? IN BOOLEAN MODE)", booleanMode)
+	}
+
+	hits := []*search.Hit{}
+	err := db.Order(order).
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// scope matching every term and phrase anywhere in the title or body, the words
+// only hold letters and digits so they need no escaping
+func matchLike(query *search.Query) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, text := range append(append([]string{}, query.Terms...), query.Phrases...) {
+			db = db.Where("(title LIKE ? OR body LIKE ?)", "%"+text+"%", "%"+text+"%")
+		}
+		for _, text := range query.Excluded {
+			db = db.Where("title NOT LIKE ? AND body NOT LIKE ?", "%"+text+"%", "%"+text+"%")
+		}
+		return db
+	}
+}
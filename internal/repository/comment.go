@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type ICommentRepository interface {
+	SelectByArticleID(ctx context.Context, articleID int) ([]*models.CommentModel, error)
+	SelectByID(ctx context.Context, id int) (*models.CommentModel, error)
+	Create(ctx context.Context, comment *models.CommentModel) (*models.CommentModel, error)
+	Update(ctx context.Context, comment *models.CommentModel) error
+	Delete(ctx context.Context, comment *models.CommentModel) error
+	DeleteByArticleID(ctx context.Context, articleID int) error
+	Count(ctx context.Context) (int64, error)
+}
+
+type commentRepository repositoryType
+
+// deleted comments are included so the replies under them keep their thread
+func (r *commentRepository) SelectByArticleID(ctx context.Context, articleID int) ([]*models.CommentModel, error) {
+	comments := []*models.CommentModel{}
+	err := getDB(ctx, r.DB).Unscoped().Preload("Author").Where("article_id = ?", articleID).Order("created_at, id").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) SelectByID(ctx context.Context, id int) (*models.CommentModel, error) {
+	comment := &models.CommentModel{}
+	err := getDB(ctx, r.DB).Preload("Author").Where("id = ?", id).First(comment).Error
+	if err != nil {
+		return nil, translateError(err, payload.ErrCommentNotFound)
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.CommentModel) (*models.CommentModel, error) {
+	err := getDB(ctx, r.DB).Omit(clause.Associations).Create(comment).Error
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *models.CommentModel) error {
+	err := getDB(ctx, r.DB).Omit(clause.Associations).Save(&comment).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, comment *models.CommentModel) error {
+	err := getDB(ctx, r.DB).Delete(&comment).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *commentRepository) DeleteByArticleID(ctx context.Context, articleID int) error {
+	err := getDB(ctx, r.DB).Where("article_id = ?", articleID).Delete(&models.CommentModel{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *commentRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	err := getDB(ctx, r.DB).Model(&models.CommentModel{}).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type apiKeyRepository store
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKeyModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, row := range r.tables.apiKeys {
+		if row.KeyHash == key.KeyHash {
+			return ErrDuplicateKey
+		}
+	}
+
+	key.BeforeCreate(nil)
+	r.tables.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r *apiKeyRepository) SelectByUserID(ctx context.Context, userID string) ([]*models.APIKeyModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := []*models.APIKeyModel{}
+	for _, key := range r.tables.apiKeys {
+		key := key
+		if key.UserID == userID {
+			keys = append(keys, &key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func (r *apiKeyRepository) SelectByHash(ctx context.Context, keyHash string) (*models.APIKeyModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.tables.apiKeys {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, payload.ErrAPIKeyInvalid
+}
+
+func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	keys, err := r.SelectByUserID(ctx, userID)
+	return int64(len(keys)), err
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.tables.apiKeys[id]
+	if !ok {
+		return nil
+	}
+	key.LastUsedAt = &usedAt
+	r.tables.apiKeys[id] = key
+	return nil
+}
+
+// returns false when the user has no key with the id
+func (r *apiKeyRepository) Delete(ctx context.Context, id string, userID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.tables.apiKeys[id]
+	if !ok || key.UserID != userID {
+		return false, nil
+	}
+	delete(r.tables.apiKeys, id)
+	return true, nil
+}
@@ -0,0 +1,323 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/common"
+)
+
+type articleRepository store
+
+// the article with its author, tags and number of visible comments, the caller
+// holds the lock
+func (s *store) article(article models.ArticleModel) *models.ArticleModel {
+	if author, ok := s.user(article.AuthorID); ok {
+		article.Author = author
+	}
+
+	article.Tags = []*models.TagModel{}
+	for _, tagID := range s.tables.articleTags[article.ID] {
+		tag := s.tables.tags[tagID]
+		article.Tags = append(article.Tags, &tag)
+	}
+
+	for _, comment := range s.tables.comments {
+		if comment.ArticleID == article.ID && !comment.IsDeleted() && !comment.IsHidden() {
+			article.CommentCount++
+		}
+	}
+	return &article
+}
+
+func (s *store) matchArticleFilter(article *models.ArticleModel, filter *repository.ArticleFilter) bool {
+	if filter == nil {
+		return true
+	}
+
+	if filter.IDs != nil {
+		found := false
+		for _, id := range filter.IDs {
+			found = found || id == article.ID
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(filter.Tags) == 0 {
+		return true
+	}
+
+	matched := 0
+	for _, tagID := range s.tables.articleTags[article.ID] {
+		for _, name := range filter.Tags {
+			if strings.EqualFold(s.tables.tags[tagID].Name, name) {
+				matched++
+				break
+			}
+		}
+	}
+	if filter.MatchAllTags {
+		return matched == len(filter.Tags)
+	}
+	return matched > 0
+}
+
+// compare the articles on the column, ties are broken by the id
+func compareArticles(a *models.ArticleModel, b *models.ArticleModel, column string) int {
+	res := 0
+	switch column {
+	case "title":
+		res = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "updated_at":
+		var aTime, bTime time.Time
+		if a.UpdatedAt != nil {
+			aTime = *a.UpdatedAt
+		}
+		if b.UpdatedAt != nil {
+			bTime = *b.UpdatedAt
+		}
+		res = compareTime(aTime, bTime)
+	default:
+		res = compareTime(a.CreatedAt, b.CreatedAt)
+	}
+
+	if res == 0 {
+		res = a.ID - b.ID
+	}
+	return res
+}
+
+func compareTime(a time.Time, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	}
+	if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+// the same order as the sort scope of the database repository
+func sortArticles(articles []*models.ArticleModel, filter *repository.ArticleFilter) {
+	if filter != nil && len(filter.IDs) > 0 && filter.Sort == "" {
+		position := map[int]int{}
+		for i, id := range filter.IDs {
+			position[id] = i
+		}
+		sort.SliceStable(articles, func(i, j int) bool {
+			return position[articles[i].ID] < position[articles[j].ID]
+		})
+		return
+	}
+
+	order := repository.DEFAULT_ARTICLE_SORT
+	if filter != nil && filter.Sort != "" {
+		order = filter.Sort
+	}
+
+	desc := strings.HasPrefix(order, "-")
+	column := strings.TrimPrefix(order, "-")
+	if column != "created_at" && column != "updated_at" && column != "title" {
+		column, desc = "created_at", true
+	}
+
+	sort.Slice(articles, func(i, j int) bool {
+		res := compareArticles(articles[i], articles[j], column)
+		if desc {
+			return res > 0
+		}
+		return res < 0
+	})
+}
+
+// continue the listing from the cursor like the keyset scope of the database repository
+func keyset(articles []*models.ArticleModel, filter *repository.ArticleFilter) []*models.ArticleModel {
+	desc := filter == nil || filter.Sort != "created_at"
+	if filter != nil && filter.Cursor != nil && filter.Cursor.Before {
+		desc = !desc
+	}
+
+	sort.Slice(articles, func(i, j int) bool {
+		res := compareArticles(articles[i], articles[j], "created_at")
+		if desc {
+			return res > 0
+		}
+		return res < 0
+	})
+
+	if filter == nil || filter.Cursor == nil {
+		return articles
+	}
+
+	cursor := &models.ArticleModel{ID: filter.Cursor.ID, CreatedAt: filter.Cursor.CreatedAt}
+	res := []*models.ArticleModel{}
+	for _, article := range articles {
+		cmp := compareArticles(article, cursor, "created_at")
+		if (desc && cmp < 0) || (!desc && cmp > 0) {
+			res = append(res, article)
+		}
+	}
+	return res
+}
+
+func (r *articleRepository) list(match func(article *models.ArticleModel) bool, filter *repository.ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	articles := []*models.ArticleModel{}
+	for _, article := range r.tables.articles {
+		article := article
+		if article.DeletedAt.Valid || !match(&article) || !s.matchArticleFilter(&article, filter) {
+			continue
+		}
+		articles = append(articles, s.article(article))
+	}
+
+	// keyset pages are not counted, like in the database repository
+	if pagination != nil && pagination.CursorMode {
+		articles = keyset(articles, filter)
+		if int64(len(articles)) > pagination.Limit {
+			articles = articles[:pagination.Limit]
+		}
+		return articles, 0, nil
+	}
+
+	sortArticles(articles, filter)
+	return paginate(articles, pagination), int64(len(articles)), nil
+}
+
+func (r *articleRepository) GetAll(ctx context.Context, filter *repository.ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	return r.list((*models.ArticleModel).IsPublished, filter, pagination)
+}
+
+func (r *articleRepository) SelectByID(ctx context.Context, id int) (*models.ArticleModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	article, ok := r.tables.articles[id]
+	if !ok || article.DeletedAt.Valid {
+		return nil, payload.ErrArticleNotFound
+	}
+	return (*store)(r).article(article), nil
+}
+
+func (r *articleRepository) SelectByAuthorID(ctx context.Context, authorID string, filter *repository.ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	return r.list(func(article *models.ArticleModel) bool {
+		return article.AuthorID == authorID && article.IsPublished()
+	}, filter, pagination)
+}
+
+func (r *articleRepository) SelectByAuthorIDAndStatus(ctx context.Context, authorID string, status string, filter *repository.ArticleFilter, pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+	return r.list(func(article *models.ArticleModel) bool {
+		return article.AuthorID == authorID && (status == "" || article.Status == status)
+	}, filter, pagination)
+}
+
+// the associations are not saved, like the database repository omitting them
+func (r *articleRepository) save(article *models.ArticleModel) {
+	row := *article
+	row.Author = nil
+	row.Tags = nil
+	row.CommentCount = 0
+	r.tables.articles[row.ID] = row
+}
+
+func (r *articleRepository) Create(ctx context.Context, article *models.ArticleModel) (*models.ArticleModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	article.BeforeCreate(nil)
+	article.ID = r.tables.nextID("articles")
+	updatedAt := article.CreatedAt
+	article.UpdatedAt = &updatedAt
+	r.save(article)
+	return article, nil
+}
+
+func (r *articleRepository) Delete(ctx context.Context, article *models.ArticleModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.tables.articles[article.ID]
+	if !ok || row.DeletedAt.Valid {
+		return nil
+	}
+	row.DeletedAt = deletedAt(time.Now())
+	r.tables.articles[row.ID] = row
+	return nil
+}
+
+func (r *articleRepository) Update(ctx context.Context, article *models.ArticleModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	article.UpdatedAt = &now
+	r.save(article)
+	return nil
+}
+
+func (r *articleRepository) ReplaceTags(ctx context.Context, article *models.ArticleModel, tags []*models.TagModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tagIDs := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	r.tables.articleTags[article.ID] = tagIDs
+	article.Tags = tags
+	return nil
+}
+
+func (r *articleRepository) PublishScheduled(ctx context.Context, now time.Time, limit int) ([]*models.ArticleModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	articles := []*models.ArticleModel{}
+	for _, article := range r.tables.articles {
+		article := article
+		if article.DeletedAt.Valid || article.Status != models.ARTICLE_STATUS_SCHEDULED || article.PublishAt == nil || article.PublishAt.After(now) {
+			continue
+		}
+		articles = append(articles, &article)
+	}
+
+	sort.Slice(articles, func(i, j int) bool {
+		return articles[i].PublishAt.Before(*articles[j].PublishAt)
+	})
+	if len(articles) > limit {
+		articles = articles[:limit]
+	}
+
+	updatedAt := time.Now()
+	for _, article := range articles {
+		article.Status = models.ARTICLE_STATUS_PUBLISHED
+		article.PublishedAt = article.PublishAt
+		article.PublishAt = nil
+		article.UpdatedAt = &updatedAt
+		r.save(article)
+	}
+	return articles, nil
+}
+
+func (r *articleRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := map[string]int64{}
+	for _, article := range r.tables.articles {
+		if !article.DeletedAt.Valid {
+			res[article.Status]++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type articleRevisionRepository store
+
+func (r *articleRevisionRepository) SelectByArticleID(ctx context.Context, articleID int) ([]*models.ArticleRevisionModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisions := []*models.ArticleRevisionModel{}
+	for _, revision := range r.tables.revisions {
+		revision := revision
+		if revision.ArticleID == articleID {
+			revisions = append(revisions, &revision)
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+func (r *articleRevisionRepository) SelectByArticleIDAndRevision(ctx context.Context, articleID int, revision int) (*models.ArticleRevisionModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, articleRevision := range r.tables.revisions {
+		if articleRevision.ArticleID == articleID && articleRevision.Revision == revision {
+			return &articleRevision, nil
+		}
+	}
+	return nil, payload.ErrRevisionNotFound
+}
+
+func (r *articleRevisionRepository) Create(ctx context.Context, revision *models.ArticleRevisionModel) (*models.ArticleRevisionModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := 0
+	for _, articleRevision := range r.tables.revisions {
+		if articleRevision.ArticleID == revision.ArticleID && articleRevision.Revision > latest {
+			latest = articleRevision.Revision
+		}
+	}
+
+	revision.BeforeCreate(nil)
+	revision.ID = r.tables.nextID("article_revisions")
+	revision.Revision = latest + 1
+	r.tables.revisions[revision.ID] = *revision
+	return revision, nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type commentRepository store
+
+// the comment with its author, the caller holds the lock
+func (s *store) comment(comment models.CommentModel) *models.CommentModel {
+	if author, ok := s.user(comment.AuthorID); ok {
+		comment.Author = author
+	}
+	return &comment
+}
+
+// deleted comments are included so the replies under them keep their thread
+func (r *commentRepository) SelectByArticleID(ctx context.Context, articleID int) ([]*models.CommentModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comments := []*models.CommentModel{}
+	for _, comment := range r.tables.comments {
+		if comment.ArticleID == articleID {
+			comments = append(comments, (*store)(r).comment(comment))
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r *commentRepository) SelectByID(ctx context.Context, id int) (*models.CommentModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.tables.comments[id]
+	if !ok || comment.IsDeleted() {
+		return nil, payload.ErrCommentNotFound
+	}
+	return (*store)(r).comment(comment), nil
+}
+
+func (r *commentRepository) save(comment *models.CommentModel) {
+	row := *comment
+	row.Author = nil
+	r.tables.comments[row.ID] = row
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.CommentModel) (*models.CommentModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment.BeforeCreate(nil)
+	comment.ID = r.tables.nextID("comments")
+	updatedAt := comment.CreatedAt
+	comment.UpdatedAt = &updatedAt
+	r.save(comment)
+	return comment, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *models.CommentModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	comment.UpdatedAt = &now
+	r.save(comment)
+	return nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, comment *models.CommentModel) error {
+	return r.deleteWhere(func(row *models.CommentModel) bool {
+		return row.ID == comment.ID
+	})
+}
+
+func (r *commentRepository) DeleteByArticleID(ctx context.Context, articleID int) error {
+	return r.deleteWhere(func(row *models.CommentModel) bool {
+		return row.ArticleID == articleID
+	})
+}
+
+func (r *commentRepository) deleteWhere(match func(row *models.CommentModel) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, row := range r.tables.comments {
+		row := row
+		if row.IsDeleted() || !match(&row) {
+			continue
+		}
+		row.DeletedAt = deletedAt(now)
+		r.tables.comments[id] = row
+	}
+	return nil
+}
+
+func (r *commentRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total int64
+	for _, comment := range r.tables.comments {
+		if !comment.IsDeleted() {
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,264 @@
+// Package memory keeps the repositories in the memory of the process, so the
+// usecases and the api can run in tests without mysql and redis. The rows are
+// copied in and out, a model returned by a repository can be changed freely.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/common"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateKey is returned where the database would fail on a unique key
+var ErrDuplicateKey = errors.New("memory: duplicate key")
+
+// the rows of the database tables, copied as a whole when a transaction starts
+type tables struct {
+	users         map[string]models.UserModel
+	articles      map[int]models.ArticleModel
+	articleTags   map[int][]int
+	revisions     map[int]models.ArticleRevisionModel
+	comments      map[int]models.CommentModel
+	tags          map[int]models.TagModel
+	apiKeys       map[string]models.APIKeyModel
+	recoveryCodes map[int]models.RecoveryCodeModel
+	identities    map[int]models.UserIdentityModel
+	// the last auto increment id given out per table
+	sequences map[string]int
+}
+
+func newTables() *tables {
+	return &tables{
+		users:         map[string]models.UserModel{},
+		articles:      map[int]models.ArticleModel{},
+		articleTags:   map[int][]int{},
+		revisions:     map[int]models.ArticleRevisionModel{},
+		comments:      map[int]models.CommentModel{},
+		tags:          map[int]models.TagModel{},
+		apiKeys:       map[string]models.APIKeyModel{},
+		recoveryCodes: map[int]models.RecoveryCodeModel{},
+		identities:    map[int]models.UserIdentityModel{},
+		sequences:     map[string]int{},
+	}
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	res := make(map[K]V, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+// the slices of article tags are always replaced and never changed in place,
+// so they can be shared by the copies
+func (t *tables) clone() *tables {
+	return &tables{
+		users:         cloneMap(t.users),
+		articles:      cloneMap(t.articles),
+		articleTags:   cloneMap(t.articleTags),
+		revisions:     cloneMap(t.revisions),
+		comments:      cloneMap(t.comments),
+		tags:          cloneMap(t.tags),
+		apiKeys:       cloneMap(t.apiKeys),
+		recoveryCodes: cloneMap(t.recoveryCodes),
+		identities:    cloneMap(t.identities),
+		sequences:     cloneMap(t.sequences),
+	}
+}
+
+func (t *tables) nextID(table string) int {
+	t.sequences[table]++
+	return t.sequences[table]
+}
+
+// an entry of the key value part, standing in for redis
+type entry struct {
+	value     string
+	set       map[string]bool
+	expiresAt time.Time
+}
+
+type store struct {
+	mu      sync.Mutex
+	tables  *tables
+	entries map[string]*entry
+}
+
+func NewRepository() *repository.Repository {
+	s := &store{tables: newTables(), entries: map[string]*entry{}}
+	return &repository.Repository{
+		User:            (*userRepository)(s),
+		Article:         (*articleRepository)(s),
+		ArticleRevision: (*articleRevisionRepository)(s),
+		Comment:         (*commentRepository)(s),
+		Session:         (*sessionRepository)(s),
+		OneTimeToken:    (*oneTimeTokenRepository)(s),
+		Throttle:        (*throttleRepository)(s),
+		RecoveryCode:    (*recoveryCodeRepository)(s),
+		APIKey:          (*apiKeyRepository)(s),
+		UserIdentity:    (*userIdentityRepository)(s),
+		Tag:             (*tagRepository)(s),
+		Tx:              (*tx)(s),
+	}
+}
+
+type tx store
+
+// the tables are copied when the transaction starts and put back when it fails,
+// a transaction inside another one does the same so it works like a savepoint.
+// other callers see the changes before the commit, tests run one request at a time
+func (t *tx) DoInTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	t.mu.Lock()
+	snapshot := t.tables.clone()
+	t.mu.Unlock()
+
+	defer func() {
+		if p := recover(); p != nil {
+			t.rollback(snapshot)
+			panic(p)
+		}
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		t.rollback(snapshot)
+	}
+	return
+}
+
+func (t *tx) rollback(snapshot *tables) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tables = snapshot
+}
+
+// the entry under the key, expired entries are removed on the way. the caller
+// holds the lock for all the key value helpers
+func (s *store) entry(key string) *entry {
+	e, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !e.expiresAt.IsZero() && !time.Now().Before(e.expiresAt) {
+		delete(s.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (s *store) get(key string) (string, error) {
+	e := s.entry(key)
+	if e == nil || e.set != nil {
+		return "", redis.Nil
+	}
+	return e.value, nil
+}
+
+func (s *store) set(key string, value string, expiration time.Duration) {
+	e := &entry{value: value}
+	s.entries[key] = e
+	s.expire(key, expiration)
+}
+
+// set the key when it does not exist yet, returns false when it did
+func (s *store) setNX(key string, expiration time.Duration) bool {
+	if s.entry(key) != nil {
+		return false
+	}
+	s.set(key, "1", expiration)
+	return true
+}
+
+func (s *store) del(keys ...string) {
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+}
+
+// a zero expiration keeps the key until it is deleted
+func (s *store) expire(key string, expiration time.Duration) {
+	e := s.entry(key)
+	if e == nil {
+		return
+	}
+	e.expiresAt = time.Time{}
+	if expiration > 0 {
+		e.expiresAt = time.Now().Add(expiration)
+	}
+}
+
+// how long the key lives, zero when it does not exist and -1 when it does not expire
+func (s *store) ttl(key string) time.Duration {
+	e := s.entry(key)
+	if e == nil {
+		return 0
+	}
+	if e.expiresAt.IsZero() {
+		return -1
+	}
+	return time.Until(e.expiresAt)
+}
+
+func (s *store) addToSet(key string, members ...string) {
+	e := s.entry(key)
+	if e == nil || e.set == nil {
+		e = &entry{set: map[string]bool{}}
+		s.entries[key] = e
+	}
+	for _, member := range members {
+		e.set[member] = true
+	}
+}
+
+func (s *store) removeFromSet(key string, members ...string) {
+	e := s.entry(key)
+	if e == nil || e.set == nil {
+		return
+	}
+	for _, member := range members {
+		delete(e.set, member)
+	}
+}
+
+func (s *store) setMembers(key string) []string {
+	members := []string{}
+	e := s.entry(key)
+	if e == nil {
+		return members
+	}
+	for member := range e.set {
+		members = append(members, member)
+	}
+	return members
+}
+
+// the rows of the page, all of them without a pagination
+func paginate[T any](rows []T, pagination *common.PaginationRequest) []T {
+	if pagination == nil {
+		return rows
+	}
+
+	offset := int(pagination.Offset)
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	rows = rows[offset:]
+
+	if pagination.Limit > 0 && int(pagination.Limit) < len(rows) {
+		rows = rows[:pagination.Limit]
+	}
+	return rows
+}
+
+func deletedAt(now time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: now, Valid: true}
+}
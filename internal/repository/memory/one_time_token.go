@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+type oneTimeTokenRepository store
+
+func oneTimeTokenKey(purpose string, tokenHash string) string {
+	return purpose + "_TOKEN_" + tokenHash
+}
+
+func userOneTimeTokenKey(purpose string, userID string) string {
+	return "USER_" + purpose + "_TOKEN_" + userID
+}
+
+// store the token for the user, the token they got before stops working
+func (r *oneTimeTokenRepository) Create(ctx context.Context, purpose string, userID string, tokenHash string, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	previous, err := s.get(userOneTimeTokenKey(purpose, userID))
+	if err == nil {
+		s.del(oneTimeTokenKey(purpose, previous))
+	}
+	s.set(oneTimeTokenKey(purpose, tokenHash), userID, expiration)
+	s.set(userOneTimeTokenKey(purpose, userID), tokenHash, expiration)
+	return nil
+}
+
+// get the user of the token without using it up
+func (r *oneTimeTokenRepository) Select(ctx context.Context, purpose string, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return (*store)(r).get(oneTimeTokenKey(purpose, tokenHash))
+}
+
+// get the user of the token and delete it so it can only be used once,
+// returns redis.Nil when the token does not exist or expired
+func (r *oneTimeTokenRepository) Consume(ctx context.Context, purpose string, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	userID, err := s.get(oneTimeTokenKey(purpose, tokenHash))
+	if err != nil {
+		return "", err
+	}
+	s.del(oneTimeTokenKey(purpose, tokenHash), userOneTimeTokenKey(purpose, userID))
+	return userID, nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type recoveryCodeRepository store
+
+func (r *recoveryCodeRepository) Create(ctx context.Context, codes []*models.RecoveryCodeModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range codes {
+		code.BeforeCreate(nil)
+		code.ID = r.tables.nextID("user_recovery_codes")
+		r.tables.recoveryCodes[code.ID] = *code
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.tables.recoveryCodes {
+		if code.UserID == userID {
+			delete(r.tables.recoveryCodes, id)
+		}
+	}
+	return nil
+}
+
+// mark the code as used, returns false when the code does not exist or was used before
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID string, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.tables.recoveryCodes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			now := time.Now()
+			code.UsedAt = &now
+			r.tables.recoveryCodes[id] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type sessionRepository store
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.SessionModel, expiration time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	s.set(repository.SESSION_KEY+session.ID, string(data), expiration)
+	s.addToSet(repository.USER_SESSIONS_KEY+session.UserID, session.ID)
+	return nil
+}
+
+func (r *sessionRepository) SelectByID(ctx context.Context, id string) (*models.SessionModel, error) {
+	r.mu.Lock()
+	data, err := (*store)(r).get(repository.SESSION_KEY + id)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.SessionModel{}
+	err = json.Unmarshal([]byte(data), session)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) SelectByUserID(ctx context.Context, userID string) ([]*models.SessionModel, error) {
+	r.mu.Lock()
+	ids := (*store)(r).setMembers(repository.USER_SESSIONS_KEY + userID)
+	r.mu.Unlock()
+
+	sessions := []*models.SessionModel{}
+	for _, id := range ids {
+		session, err := r.SelectByID(ctx, id)
+		if err == redis.Nil {
+			// session already expired, clean up the index
+			r.mu.Lock()
+			(*store)(r).removeFromSet(repository.USER_SESSIONS_KEY+userID, id)
+			r.mu.Unlock()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// update the stored session while keeping its expiration
+func (r *sessionRepository) Touch(ctx context.Context, session *models.SessionModel) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	e := s.entry(repository.SESSION_KEY + session.ID)
+	if e == nil || e.expiresAt.IsZero() {
+		return redis.Nil
+	}
+	e.value = string(data)
+	return nil
+}
+
+func (r *sessionRepository) Extend(ctx context.Context, session *models.SessionModel, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	s.expire(repository.SESSION_KEY+session.ID, expiration)
+	s.expire(repository.SESSION_REFRESH_TOKENS_KEY+session.ID, expiration)
+	return nil
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, session *models.SessionModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delete(session)
+	return nil
+}
+
+// the caller holds the lock
+func (r *sessionRepository) delete(session *models.SessionModel) {
+	s := (*store)(r)
+	for _, tokenHash := range s.setMembers(repository.SESSION_REFRESH_TOKENS_KEY + session.ID) {
+		s.del(repository.REFRESH_TOKEN_KEY+tokenHash, repository.REFRESH_TOKEN_USED_KEY+tokenHash)
+	}
+	s.del(repository.SESSION_KEY+session.ID, repository.SESSION_REFRESH_TOKENS_KEY+session.ID)
+	s.removeFromSet(repository.USER_SESSIONS_KEY+session.UserID, session.ID)
+}
+
+func (r *sessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	for _, id := range s.setMembers(repository.USER_SESSIONS_KEY + userID) {
+		r.delete(&models.SessionModel{ID: id, UserID: userID})
+	}
+	s.del(repository.USER_SESSIONS_KEY + userID)
+	return nil
+}
+
+func (r *sessionRepository) SaveRefreshToken(ctx context.Context, session *models.SessionModel, tokenHash string, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	s.set(repository.REFRESH_TOKEN_KEY+tokenHash, session.ID, expiration)
+	s.addToSet(repository.SESSION_REFRESH_TOKENS_KEY+session.ID, tokenHash)
+	s.expire(repository.SESSION_REFRESH_TOKENS_KEY+session.ID, expiration)
+	return nil
+}
+
+func (r *sessionRepository) SelectSessionIDByRefreshToken(ctx context.Context, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return (*store)(r).get(repository.REFRESH_TOKEN_KEY + tokenHash)
+}
+
+// mark the refresh token as used, returns false when it was already used before
+func (r *sessionRepository) MarkRefreshTokenUsed(ctx context.Context, tokenHash string, expiration time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return (*store)(r).setNX(repository.REFRESH_TOKEN_USED_KEY+tokenHash, expiration), nil
+}
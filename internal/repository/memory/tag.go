@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type tagRepository store
+
+// list tags used by published articles together with how many articles use them
+func (r *tagRepository) SelectAllWithArticleCount(ctx context.Context) ([]*models.TagModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := map[int]int64{}
+	for articleID, tagIDs := range r.tables.articleTags {
+		article, ok := r.tables.articles[articleID]
+		if !ok || article.DeletedAt.Valid || !article.IsPublished() {
+			continue
+		}
+		for _, tagID := range tagIDs {
+			counts[tagID]++
+		}
+	}
+
+	tags := []*models.TagModel{}
+	for tagID, count := range counts {
+		tag := r.tables.tags[tagID]
+		tag.ArticleCount = count
+		tags = append(tags, &tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].ArticleCount != tags[j].ArticleCount {
+			return tags[i].ArticleCount > tags[j].ArticleCount
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// get the tags by name, the missing ones are created
+func (r *tagRepository) SelectOrCreateByNames(ctx context.Context, names []string) ([]*models.TagModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byName := map[string]models.TagModel{}
+	for _, tag := range r.tables.tags {
+		byName[tag.Name] = tag
+	}
+
+	tags := []*models.TagModel{}
+	for _, name := range names {
+		tag, ok := byName[name]
+		if !ok {
+			tag = models.TagModel{Name: name}
+			tag.BeforeCreate(nil)
+			tag.ID = r.tables.nextID("tags")
+			r.tables.tags[tag.ID] = tag
+			byName[name] = tag
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/repository"
+)
+
+type throttleRepository store
+
+// allow the action once per interval, returns false when it was already done within it
+func (r *throttleRepository) Allow(ctx context.Context, key string, interval time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return (*store)(r).setNX(repository.THROTTLE_KEY+key, interval), nil
+}
+
+// count one more attempt, returns the attempts within the window that started with the first one
+func (r *throttleRepository) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := (*store)(r)
+	e := s.entry(repository.THROTTLE_KEY + key)
+	if e == nil {
+		s.set(repository.THROTTLE_KEY+key, "1", window)
+		return 1, nil
+	}
+
+	count, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count++
+	e.value = strconv.FormatInt(count, 10)
+	return count, nil
+}
+
+func (r *throttleRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	(*store)(r).del(repository.THROTTLE_KEY + key)
+	return nil
+}
+
+func (r *throttleRepository) Lock(ctx context.Context, key string, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	(*store)(r).set(repository.LOCK_KEY+key, "1", duration)
+	return nil
+}
+
+// how long the key stays locked, zero when it is not locked
+func (r *throttleRepository) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ttl := (*store)(r).ttl(repository.LOCK_KEY + key)
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
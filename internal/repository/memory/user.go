@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/common"
+)
+
+type userRepository store
+
+// the user when it exists and is not deleted, the caller holds the lock
+func (s *store) user(id string) (*models.UserModel, bool) {
+	user, ok := s.tables.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, false
+	}
+	return &user, true
+}
+
+func (r *userRepository) SelectByID(ctx context.Context, id string) (*models.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := (*store)(r).user(id)
+	if !ok {
+		return nil, payload.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *userRepository) SelectByEmail(ctx context.Context, email string) (*models.UserModel, error) {
+	return r.first(func(user *models.UserModel) bool {
+		return strings.EqualFold(user.Email, email)
+	}, payload.ErrUserNotFound)
+}
+
+func (r *userRepository) SelectByName(ctx context.Context, name string) (*models.UserModel, error) {
+	return r.first(func(user *models.UserModel) bool {
+		return strings.Contains(strings.ToLower(user.Name), strings.ToLower(name))
+	}, payload.ErrAuthorNotFound)
+}
+
+// the matching user with the lowest id, like gorm First
+func (r *userRepository) first(match func(user *models.UserModel) bool, notFound error) (*models.UserModel, error) {
+	var res *models.UserModel
+	for _, user := range r.all(match) {
+		if res == nil || user.ID < res.ID {
+			res = user
+		}
+	}
+	if res == nil {
+		return nil, notFound
+	}
+	return res, nil
+}
+
+// the users matching, the newest first
+func (r *userRepository) all(match func(user *models.UserModel) bool) []*models.UserModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := []*models.UserModel{}
+	for _, user := range r.tables.users {
+		user := user
+		if user.DeletedAt.Valid || !match(&user) {
+			continue
+		}
+		users = append(users, &user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.After(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
+
+func (r *userRepository) SelectAll(ctx context.Context, pagination *common.PaginationRequest) ([]*models.UserModel, int64, error) {
+	users := r.all(func(user *models.UserModel) bool { return true })
+	return paginate(users, pagination), int64(len(users)), nil
+}
+
+func (r *userRepository) CountByRole(ctx context.Context) (map[string]int64, error) {
+	res := map[string]int64{}
+	for _, user := range r.all(func(user *models.UserModel) bool { return true }) {
+		res[user.Role]++
+	}
+	return res, nil
+}
+
+func (r *userRepository) CountBanned(ctx context.Context) (int64, error) {
+	return int64(len(r.all((*models.UserModel).IsBanned))), nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.UserModel) (*models.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.BeforeCreate(nil)
+	updatedAt := user.CreatedAt
+	user.UpdatedAt = &updatedAt
+	r.tables.users[user.ID] = *user
+	return user, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, user *models.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := (*store)(r).user(user.ID)
+	if !ok {
+		return nil
+	}
+	stored.DeletedAt = deletedAt(time.Now())
+	r.tables.users[user.ID] = *stored
+	return nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *models.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	user.UpdatedAt = &now
+	r.tables.users[user.ID] = *user
+	return nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+)
+
+type userIdentityRepository store
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentityModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, row := range r.tables.identities {
+		if row.Issuer == identity.Issuer && row.Subject == identity.Subject {
+			return ErrDuplicateKey
+		}
+	}
+
+	identity.BeforeCreate(nil)
+	identity.ID = r.tables.nextID("user_identities")
+	r.tables.identities[identity.ID] = *identity
+	return nil
+}
+
+func (r *userIdentityRepository) SelectByIssuerAndSubject(ctx context.Context, issuer string, subject string) (*models.UserIdentityModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.tables.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, payload.ErrUserNotFound
+}
+
+func (r *userIdentityRepository) CreateLogin(ctx context.Context, stateHash string, login *models.OIDCLoginModel, expiration time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	(*store)(r).set(repository.OIDC_LOGIN_KEY+stateHash, string(data), expiration)
+	return nil
+}
+
+// get the login of the state and delete it so the state can only be used once,
+// returns redis.Nil when the state does not exist or expired
+func (r *userIdentityRepository) ConsumeLogin(ctx context.Context, stateHash string) (*models.OIDCLoginModel, error) {
+	r.mu.Lock()
+	s := (*store)(r)
+	data, err := s.get(repository.OIDC_LOGIN_KEY + stateHash)
+	s.del(repository.OIDC_LOGIN_KEY + stateHash)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	login := &models.OIDCLoginModel{}
+	err = json.Unmarshal([]byte(data), login)
+	if err != nil {
+		return nil, err
+	}
+	return login, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// kinds of one time tokens, each user has at most one active token of a kind
+const (
+	TOKEN_PURPOSE_PASSWORD_RESET = "PASSWORD_RESET"
+	TOKEN_PURPOSE_2FA_CHALLENGE  = "2FA_CHALLENGE"
+)
+
+type IOneTimeTokenRepository interface {
+	Create(ctx context.Context, purpose string, userID string, tokenHash string, expiration time.Duration) error
+	Select(ctx context.Context, purpose string, tokenHash string) (string, error)
+	Consume(ctx context.Context, purpose string, tokenHash string) (string, error)
+}
+
+type oneTimeTokenRepository repositoryType
+
+func oneTimeTokenKey(purpose string, tokenHash string) string {
+	return purpose + "_TOKEN_" + tokenHash
+}
+
+func userOneTimeTokenKey(purpose string, userID string) string {
+	return "USER_" + purpose + "_TOKEN_" + userID
+}
+
+// store the token for the user, the token they got before stops working
+func (r *oneTimeTokenRepository) Create(ctx context.Context, purpose string, userID string, tokenHash string, expiration time.Duration) error {
+	previous, err := r.Redis.Get(ctx, userOneTimeTokenKey(purpose, userID)).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := r.Redis.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, oneTimeTokenKey(purpose, previous))
+	}
+	pipe.Set(ctx, oneTimeTokenKey(purpose, tokenHash), userID, expiration)
+	pipe.Set(ctx, userOneTimeTokenKey(purpose, userID), tokenHash, expiration)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// get the user of the token without using it up
+func (r *oneTimeTokenRepository) Select(ctx context.Context, purpose string, tokenHash string) (string, error) {
+	return r.Redis.Get(ctx, oneTimeTokenKey(purpose, tokenHash)).Result()
+}
+
+// get the user of the token and delete it in one step so it can only be used once,
+// returns redis.Nil when the token does not exist or expired
+func (r *oneTimeTokenRepository) Consume(ctx context.Context, purpose string, tokenHash string) (string, error) {
+	pipe := r.Redis.TxPipeline()
+	userID := pipe.Get(ctx, oneTimeTokenKey(purpose, tokenHash))
+	pipe.Del(ctx, oneTimeTokenKey(purpose, tokenHash))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r.Redis.Del(ctx, userOneTimeTokenKey(purpose, userID.Val()))
+	return userID.Val(), nil
+}
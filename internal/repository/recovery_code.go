@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+type IRecoveryCodeRepository interface {
+	Create(ctx context.Context, codes []*models.RecoveryCodeModel) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	Use(ctx context.Context, userID string, codeHash string) (bool, error)
+}
+
+type recoveryCodeRepository repositoryType
+
+func (r *recoveryCodeRepository) Create(ctx context.Context, codes []*models.RecoveryCodeModel) error {
+	return getDB(ctx, r.DB).Create(&codes).Error
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return getDB(ctx, r.DB).Where("user_id = ?", userID).Delete(&models.RecoveryCodeModel{}).Error
+}
+
+// mark the code as used, returns false when the code does not exist or was used before
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID string, codeHash string) (bool, error) {
+	res := getDB(ctx, r.DB).Model(&models.RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
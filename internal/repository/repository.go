@@ -1,54 +1,135 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/haikalvidya/go-article/pkg/apperror"
+
+	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 )
 
 type Repository struct {
-	User    IUserRepository
-	Article IArticleRepository
-	Tx      Tx
+	User            IUserRepository
+	Article         IArticleRepository
+	ArticleRevision IArticleRevisionRepository
+	Comment         ICommentRepository
+	Session         ISessionRepository
+	OneTimeToken    IOneTimeTokenRepository
+	Throttle        IThrottleRepository
+	RecoveryCode    IRecoveryCodeRepository
+	APIKey          IAPIKeyRepository
+	UserIdentity    IUserIdentityRepository
+	Tag             ITagRepository
+	Tx              Tx
 }
 
 type repositoryType struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Redis *redis.Client
 }
 
+// turn the gorm not found error into the not found error of the domain, the
+// gorm error stays in the chain for errors.Is
+func translateError(err error, notFound *apperror.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound.Wrap(err)
+	}
+	return err
+}
+
+// Tx runs fn in a transaction, the repositories called with the context given to
+// fn take part in it. a transaction started inside another one is a savepoint, so
+// when it fails only its own changes are rolled back
 type Tx interface {
-	DoInTransaction(f func(tx *gorm.DB) error) error
+	DoInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type transaction struct {
+	db    *gorm.DB
+	depth int
 }
 
 type tx struct {
 	DB *gorm.DB
 }
 
-func (t *tx) DoInTransaction(fn func(tx *gorm.DB) error) (err error) {
-	tx := t.DB.Begin()
+func (t *tx) DoInTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if outer, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return outer.savepoint(ctx, fn)
+	}
+
+	db := t.DB.WithContext(ctx).Begin()
+	if db.Error != nil {
+		return db.Error
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
-			tx.Rollback()
+			db.Rollback()
 			panic(p)
 		}
 	}()
 
-	err = fn(tx)
+	err = fn(context.WithValue(ctx, txKey{}, &transaction{db: db}))
+	if err != nil {
+		db.Rollback()
+		return
+	}
+
+	return db.Commit().Error
+}
+
+func (t *transaction) savepoint(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	inner := &transaction{db: t.db, depth: t.depth + 1}
+	name := fmt.Sprintf("sp%d", inner.depth)
 
+	err = t.db.WithContext(ctx).SavePoint(name).Error
 	if err != nil {
-		tx.Rollback()
 		return
 	}
 
-	tx.Commit()
+	defer func() {
+		if p := recover(); p != nil {
+			t.db.RollbackTo(name)
+			panic(p)
+		}
+	}()
 
+	err = fn(context.WithValue(ctx, txKey{}, inner))
+	if err != nil {
+		t.db.WithContext(ctx).RollbackTo(name)
+	}
 	return
 }
 
-func NewRepository(db *gorm.DB) *Repository {
-	repo := &repositoryType{DB: db}
+// the transaction in the context when there is one, so repositories never need
+// to know if they are called inside a transaction
+func getDB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if t, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return t.db.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+func NewRepository(db *gorm.DB, redis *redis.Client) *Repository {
+	repo := &repositoryType{DB: db, Redis: redis}
 	return &Repository{
-		User:    (*userRepository)(repo),
-		Article: (*articleRepository)(repo),
-		Tx:      &tx{DB: db},
+		User:            (*userRepository)(repo),
+		Article:         (*articleRepository)(repo),
+		ArticleRevision: (*articleRevisionRepository)(repo),
+		Comment:         (*commentRepository)(repo),
+		Session:         (*sessionRepository)(repo),
+		OneTimeToken:    (*oneTimeTokenRepository)(repo),
+		Throttle:        (*throttleRepository)(repo),
+		RecoveryCode:    (*recoveryCodeRepository)(repo),
+		APIKey:          (*apiKeyRepository)(repo),
+		UserIdentity:    (*userIdentityRepository)(repo),
+		Tag:             (*tagRepository)(repo),
+		Tx:              &tx{DB: db},
 	}
 }
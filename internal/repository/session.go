@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	SESSION_KEY                = "SESSION_"
+	USER_SESSIONS_KEY          = "USER_SESSIONS_"
+	SESSION_REFRESH_TOKENS_KEY = "SESSION_REFRESH_TOKENS_"
+	REFRESH_TOKEN_KEY          = "REFRESH_TOKEN_"
+	REFRESH_TOKEN_USED_KEY     = "REFRESH_TOKEN_USED_"
+)
+
+type ISessionRepository interface {
+	Create(ctx context.Context, session *models.SessionModel, expiration time.Duration) error
+	SelectByID(ctx context.Context, id string) (*models.SessionModel, error)
+	SelectByUserID(ctx context.Context, userID string) ([]*models.SessionModel, error)
+	Touch(ctx context.Context, session *models.SessionModel) error
+	Extend(ctx context.Context, session *models.SessionModel, expiration time.Duration) error
+	Delete(ctx context.Context, session *models.SessionModel) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	SaveRefreshToken(ctx context.Context, session *models.SessionModel, tokenHash string, expiration time.Duration) error
+	SelectSessionIDByRefreshToken(ctx context.Context, tokenHash string) (string, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenHash string, expiration time.Duration) (bool, error)
+}
+
+type sessionRepository repositoryType
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.SessionModel, expiration time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.Redis.TxPipeline()
+	pipe.Set(ctx, SESSION_KEY+session.ID, string(data), expiration)
+	pipe.SAdd(ctx, USER_SESSIONS_KEY+session.UserID, session.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *sessionRepository) SelectByID(ctx context.Context, id string) (*models.SessionModel, error) {
+	data, err := r.Redis.Get(ctx, SESSION_KEY+id).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.SessionModel{}
+	err = json.Unmarshal([]byte(data), session)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) SelectByUserID(ctx context.Context, userID string) ([]*models.SessionModel, error) {
+	ids, err := r.Redis.SMembers(ctx, USER_SESSIONS_KEY+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*models.SessionModel{}
+	for _, id := range ids {
+		session, err := r.SelectByID(ctx, id)
+		if err == redis.Nil {
+			// session already expired, clean up the index
+			r.Redis.SRem(ctx, USER_SESSIONS_KEY+userID, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// update the stored session while keeping its expiration
+func (r *sessionRepository) Touch(ctx context.Context, session *models.SessionModel) error {
+	ttl, err := r.Redis.TTL(ctx, SESSION_KEY+session.ID).Result()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return redis.Nil
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return r.Redis.SetXX(ctx, SESSION_KEY+session.ID, string(data), ttl).Err()
+}
+
+func (r *sessionRepository) Extend(ctx context.Context, session *models.SessionModel, expiration time.Duration) error {
+	pipe := r.Redis.TxPipeline()
+	pipe.Expire(ctx, SESSION_KEY+session.ID, expiration)
+	pipe.Expire(ctx, SESSION_REFRESH_TOKENS_KEY+session.ID, expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, session *models.SessionModel) error {
+	tokenHashes, err := r.Redis.SMembers(ctx, SESSION_REFRESH_TOKENS_KEY+session.ID).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{SESSION_KEY + session.ID, SESSION_REFRESH_TOKENS_KEY + session.ID}
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, REFRESH_TOKEN_KEY+tokenHash, REFRESH_TOKEN_USED_KEY+tokenHash)
+	}
+
+	pipe := r.Redis.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.SRem(ctx, USER_SESSIONS_KEY+session.UserID, session.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *sessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	ids, err := r.Redis.SMembers(ctx, USER_SESSIONS_KEY+userID).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err = r.Delete(ctx, &models.SessionModel{ID: id, UserID: userID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.Redis.Del(ctx, USER_SESSIONS_KEY+userID).Err()
+}
+
+func (r *sessionRepository) SaveRefreshToken(ctx context.Context, session *models.SessionModel, tokenHash string, expiration time.Duration) error {
+	pipe := r.Redis.TxPipeline()
+	pipe.Set(ctx, REFRESH_TOKEN_KEY+tokenHash, session.ID, expiration)
+	pipe.SAdd(ctx, SESSION_REFRESH_TOKENS_KEY+session.ID, tokenHash)
+	pipe.Expire(ctx, SESSION_REFRESH_TOKENS_KEY+session.ID, expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *sessionRepository) SelectSessionIDByRefreshToken(ctx context.Context, tokenHash string) (string, error) {
+	return r.Redis.Get(ctx, REFRESH_TOKEN_KEY+tokenHash).Result()
+}
+
+// mark the refresh token as used, returns false when it was already used before
+func (r *sessionRepository) MarkRefreshTokenUsed(ctx context.Context, tokenHash string, expiration time.Duration) (bool, error) {
+	return r.Redis.SetNX(ctx, REFRESH_TOKEN_USED_KEY+tokenHash, 1, expiration).Result()
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/haikalvidya/go-article/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type ITagRepository interface {
+	SelectAllWithArticleCount(ctx context.Context) ([]*models.TagModel, error)
+	SelectOrCreateByNames(ctx context.Context, names []string) ([]*models.TagModel, error)
+}
+
+type tagRepository repositoryType
+
+// list tags used by published articles together with how many articles use them
+func (r *tagRepository) SelectAllWithArticleCount(ctx context.Context) ([]*models.TagModel, error) {
+	tags := []*models.TagModel{}
+	err := getDB(ctx, r.DB).Model(&models.TagModel{}).
+		Select("tags.id, tags.name, tags.created_at, COUNT(articles.id) AS article_count").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id AND articles.deleted_at IS NULL AND articles.status = ?", models.ARTICLE_STATUS_PUBLISHED).
+		Group("tags.id, tags.name, tags.created_at").
+		Order("article_count DESC, tags.name").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// get the tags by name, the missing ones are created
+func (r *tagRepository) SelectOrCreateByNames(ctx context.Context, names []string) ([]*models.TagModel, error) {
+	db := getDB(ctx, r.DB)
+	tags := []*models.TagModel{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	newTags := make([]*models.TagModel, 0, len(names))
+	for _, name := range names {
+		newTags = append(newTags, &models.TagModel{Name: name})
+	}
+
+	// another request can create the same tag at the same time, so existing names are ignored
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("name IN ?", names).Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	THROTTLE_KEY = "THROTTLE_"
+	LOCK_KEY     = "LOCK_"
+)
+
+type IThrottleRepository interface {
+	Allow(ctx context.Context, key string, interval time.Duration) (bool, error)
+	Hit(ctx context.Context, key string, window time.Duration) (int64, error)
+	Reset(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+}
+
+type throttleRepository repositoryType
+
+// allow the action once per interval, returns false when it was already done within it
+func (r *throttleRepository) Allow(ctx context.Context, key string, interval time.Duration) (bool, error) {
+	return r.Redis.SetNX(ctx, THROTTLE_KEY+key, 1, interval).Result()
+}
+
+// count one more attempt, returns the attempts within the window that started with the first one
+func (r *throttleRepository) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := r.Redis.TxPipeline()
+	count := pipe.Incr(ctx, THROTTLE_KEY+key)
+	ttl := pipe.TTL(ctx, THROTTLE_KEY+key)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// the window starts with the first attempt, a key left without a ttl gets one too
+	if ttl.Val() < 0 {
+		err = r.Redis.Expire(ctx, THROTTLE_KEY+key, window).Err()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count.Val(), nil
+}
+
+func (r *throttleRepository) Reset(ctx context.Context, key string) error {
+	return r.Redis.Del(ctx, THROTTLE_KEY+key).Err()
+}
+
+func (r *throttleRepository) Lock(ctx context.Context, key string, duration time.Duration) error {
+	return r.Redis.Set(ctx, LOCK_KEY+key, 1, duration).Err()
+}
+
+// how long the key stays locked, zero when it is not locked
+func (r *throttleRepository) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.Redis.TTL(ctx, LOCK_KEY+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -1,68 +1,115 @@
 package repository
 
 import (
-	"github.com/haikalvidya/go-article/internal/models"
+	"context"
 
-	"gorm.io/gorm"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/common"
 )
 
 type IUserRepository interface {
-	SelectByID(id string) (*models.UserModel, error)
-	SelectByEmail(email string) (*models.UserModel, error)
-	SelectByName(name string) (*models.UserModel, error)
-	CreateTx(tx *gorm.DB, user *models.UserModel) (*models.UserModel, error)
-	DeleteTx(tx *gorm.DB, user *models.UserModel) error
-	UpdateTx(tx *gorm.DB, user *models.UserModel) error
+	SelectByID(ctx context.Context, id string) (*models.UserModel, error)
+	SelectByEmail(ctx context.Context, email string) (*models.UserModel, error)
+	SelectByName(ctx context.Context, name string) (*models.UserModel, error)
+	SelectAll(ctx context.Context, pagination *common.PaginationRequest) ([]*models.UserModel, int64, error)
+	CountByRole(ctx context.Context) (map[string]int64, error)
+	CountBanned(ctx context.Context) (int64, error)
+	Create(ctx context.Context, user *models.UserModel) (*models.UserModel, error)
+	Delete(ctx context.Context, user *models.UserModel) error
+	Update(ctx context.Context, user *models.UserModel) error
 }
 
 type userRepository repositoryType
 
-func (r *userRepository) SelectByID(id string) (*models.UserModel, error) {
+func (r *userRepository) SelectByID(ctx context.Context, id string) (*models.UserModel, error) {
 	user := &models.UserModel{}
-	err := r.DB.Where("id = ?", id).First(user).Error
+	err := getDB(ctx, r.DB).Where("id = ?", id).First(user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, payload.ErrUserNotFound)
 	}
 	return user, nil
 }
 
-func (r *userRepository) SelectByEmail(email string) (*models.UserModel, error) {
+func (r *userRepository) SelectByEmail(ctx context.Context, email string) (*models.UserModel, error) {
 	user := &models.UserModel{}
-	err := r.DB.Where("email = ?", email).First(user).Error
+	// mysql compares case insensitive by itself, the other databases do not
+	err := getDB(ctx, r.DB).Where("LOWER(email) = LOWER(?)", email).First(user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, payload.ErrUserNotFound)
 	}
 	return user, nil
 }
 
-func (r *userRepository) CreateTx(tx *gorm.DB, user *models.UserModel) (*models.UserModel, error) {
-	err := tx.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *models.UserModel) (*models.UserModel, error) {
+	err := getDB(ctx, r.DB).Create(user).Error
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (r *userRepository) SelectByName(name string) (*models.UserModel, error) {
+func (r *userRepository) SelectByName(ctx context.Context, name string) (*models.UserModel, error) {
 	user := &models.UserModel{}
 	// like
-	err := r.DB.Where("name LIKE ?", "%"+name+"%").First(user).Error
+	err := getDB(ctx, r.DB).Where("LOWER(name) LIKE LOWER(?)", "%"+name+"%").First(user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, payload.ErrAuthorNotFound)
 	}
 	return user, nil
 }
 
-func (r *userRepository) DeleteTx(tx *gorm.DB, user *models.UserModel) error {
-	err := tx.Delete(&user).Error
+func (r *userRepository) SelectAll(ctx context.Context, pagination *common.PaginationRequest) ([]*models.UserModel, int64, error) {
+	var total int64
+	err := getDB(ctx, r.DB).Model(&models.UserModel{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := []*models.UserModel{}
+	err = getDB(ctx, r.DB).Order("created_at DESC").Order("id").Scopes(paginate(pagination)).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) CountByRole(ctx context.Context) (map[string]int64, error) {
+	rows := []struct {
+		Role  string
+		Total int64
+	}{}
+	err := getDB(ctx, r.DB).Model(&models.UserModel{}).Select("role, COUNT(*) AS total").Group("role").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]int64{}
+	for _, row := range rows {
+		res[row.Role] = row.Total
+	}
+	return res, nil
+}
+
+func (r *userRepository) CountBanned(ctx context.Context) (int64, error) {
+	var total int64
+	err := getDB(ctx, r.DB).Model(&models.UserModel{}).Where("banned_at IS NOT NULL").Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, user *models.UserModel) error {
+	err := getDB(ctx, r.DB).Delete(&user).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *userRepository) UpdateTx(tx *gorm.DB, user *models.UserModel) error {
-	err := tx.Save(&user).Error
+func (r *userRepository) Update(ctx context.Context, user *models.UserModel) error {
+	err := getDB(ctx, r.DB).Save(&user).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+const OIDC_LOGIN_KEY = "OIDC_LOGIN_"
+
+type IUserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentityModel) error
+	SelectByIssuerAndSubject(ctx context.Context, issuer string, subject string) (*models.UserIdentityModel, error)
+	CreateLogin(ctx context.Context, stateHash string, login *models.OIDCLoginModel, expiration time.Duration) error
+	ConsumeLogin(ctx context.Context, stateHash string) (*models.OIDCLoginModel, error)
+}
+
+type userIdentityRepository repositoryType
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentityModel) error {
+	return getDB(ctx, r.DB).Create(identity).Error
+}
+
+func (r *userIdentityRepository) SelectByIssuerAndSubject(ctx context.Context, issuer string, subject string) (*models.UserIdentityModel, error) {
+	identity := &models.UserIdentityModel{}
+	err := getDB(ctx, r.DB).Where("issuer = ? AND subject = ?", issuer, subject).First(identity).Error
+	if err != nil {
+		return nil, translateError(err, payload.ErrUserNotFound)
+	}
+	return identity, nil
+}
+
+func (r *userIdentityRepository) CreateLogin(ctx context.Context, stateHash string, login *models.OIDCLoginModel, expiration time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return r.Redis.Set(ctx, OIDC_LOGIN_KEY+stateHash, string(data), expiration).Err()
+}
+
+// get the login of the state and delete it so the state can only be used once,
+// returns redis.Nil when the state does not exist or expired
+func (r *userIdentityRepository) ConsumeLogin(ctx context.Context, stateHash string) (*models.OIDCLoginModel, error) {
+	pipe := r.Redis.TxPipeline()
+	data := pipe.Get(ctx, OIDC_LOGIN_KEY+stateHash)
+	pipe.Del(ctx, OIDC_LOGIN_KEY+stateHash)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	login := &models.OIDCLoginModel{}
+	err = json.Unmarshal([]byte(data.Val()), login)
+	if err != nil {
+		return nil, err
+	}
+	return login, nil
+}
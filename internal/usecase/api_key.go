@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/utils"
+)
+
+const (
+	API_KEY_TOKEN_PREFIX = "gak_"
+	API_KEY_SIZE         = 32
+	// length of the start of the key that is stored in plain text
+	API_KEY_VISIBLE_PREFIX_LENGTH = 12
+	MAX_API_KEYS_PER_USER         = 20
+)
+
+func (u *userUsecase) CreateAPIKey(ctx context.Context, userID string, req *payload.CreateAPIKeyRequest) (*payload.CreateAPIKeyResponse, error) {
+	count, err := u.Repo.APIKey.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MAX_API_KEYS_PER_USER {
+		return nil, payload.ErrAPIKeyLimit
+	}
+
+	random, err := utils.GenerateRandomToken(API_KEY_SIZE)
+	if err != nil {
+		return nil, err
+	}
+	key := API_KEY_TOKEN_PREFIX + random
+
+	apiKey := &models.APIKeyModel{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  key[:API_KEY_VISIBLE_PREFIX_LENGTH],
+		KeyHash: hashToken(key),
+		Scopes:  strings.Join(uniqueScopes(req.Scopes), ","),
+	}
+	err = u.Repo.APIKey.Create(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	u.securityEvent(SECURITY_EVENT_API_KEY_CREATED, "user_id", userID, "api_key_id", apiKey.ID)
+
+	return &payload.CreateAPIKeyResponse{
+		APIKeyInfo: apiKey.Info(),
+		Key:        key,
+	}, nil
+}
+
+func (u *userUsecase) GetAPIKeys(ctx context.Context, userID string) ([]*payload.APIKeyInfo, error) {
+	keys, err := u.Repo.APIKey.SelectByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*payload.APIKeyInfo{}
+	for _, key := range keys {
+		res = append(res, key.Info())
+	}
+	return res, nil
+}
+
+func (u *userUsecase) RevokeAPIKey(ctx context.Context, userID string, keyID string) error {
+	deleted, err := u.Repo.APIKey.Delete(ctx, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return payload.ErrAPIKeyNotFound
+	}
+
+	u.securityEvent(SECURITY_EVENT_API_KEY_REVOKED, "user_id", userID, "api_key_id", keyID)
+	return nil
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			res = append(res, scope)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
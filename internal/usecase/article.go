@@ -1,257 +1,423 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/haikalvidya/go-article/internal/delivery/payload"
 	"github.com/haikalvidya/go-article/internal/models"
-	"gorm.io/gorm"
+	"github.com/haikalvidya/go-article/internal/policy"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/apperror"
+	"github.com/haikalvidya/go-article/pkg/cache"
+	"github.com/haikalvidya/go-article/pkg/common"
+	"github.com/haikalvidya/go-article/pkg/search"
+	"github.com/haikalvidya/go-article/pkg/utils"
 )
 
 type IArticleUsecase interface {
-	CreateArticle(authorID string, req *payload.CreateArticleRequest) (*payload.ArticleInfo, error)
-	GetAllArticles() ([]*payload.ArticleInfo, error)
-	GetArticleByID(id int) (*payload.ArticleInfo, error)
-	GetArticlesByAuthorID(authorID string) ([]*payload.ArticleInfo, error)
-	SearchArticlesByTitleAndContent(content string) ([]*payload.ArticleInfo, error)
-	GetArticleSearchAndByAuthorID(authorID string, content string) ([]*payload.ArticleInfo, error)
-	DeleteArticleByID(id int, authorId string) error
-	UpdateArticleByID(id int, req *payload.UpdateArticleRequest, authorId string) (*payload.ArticleInfo, error)
+	CreateArticle(ctx context.Context, authorID string, req *payload.CreateArticleRequest) (*payload.ArticleInfo, error)
+	GetAllArticles(ctx context.Context, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error)
+	GetArticleByID(ctx context.Context, id int) (*payload.ArticleInfo, error)
+	GetArticlesByAuthorID(ctx context.Context, authorID string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error)
+	SearchArticlesByTitleAndContent(ctx context.Context, content string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error)
+	GetArticleSearchAndByAuthorID(ctx context.Context, authorID string, content string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error)
+	DeleteArticleByID(ctx context.Context, id int, userID string) error
+	UpdateArticleByID(ctx context.Context, id int, req *payload.UpdateArticleRequest, userID string) (*payload.ArticleInfo, error)
+	GetMyArticles(ctx context.Context, authorID string, query *payload.MyArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error)
+	GetMyArticleByID(ctx context.Context, id int, authorID string) (*payload.ArticleInfo, error)
+	PublishArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error)
+	UnpublishArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error)
+	ArchiveArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error)
+	ScheduleArticle(ctx context.Context, id int, req *payload.ScheduleArticleRequest, userID string) (*payload.ArticleInfo, error)
+	PublishScheduledArticles(ctx context.Context, limit int) (int, error)
+	ReindexSearch(ctx context.Context) (int, error)
 }
 
+const (
+	CACHE_ARTICLE_BY_ID         = "GET_ARTICLE_BY_ID_"
+	CACHE_ARTICLES_BY_AUTHOR_ID = "GET_ARTICLES_BY_AUTHOR_ID_"
+	CACHE_ALL_ARTICLES          = "GET_ALL_ARTICLES"
+	// every cached page of a listing is remembered in this set so they can be deleted together
+	CACHE_KEYS_SUFFIX = "_KEYS"
+
+	// cursor pagination only follows created_at, newest first by default
+	DEFAULT_CURSOR_SORT = "-created_at"
+
+	// length of the search result snippet in bytes
+	SEARCH_SNIPPET_SIZE = 200
+	// hits taken from the search index, results past it are not listed
+	SEARCH_MAX_HITS           = 1000
+	SEARCH_REINDEX_BATCH_SIZE = 500
+)
+
 type articleUsecase usecaseType
 
-func (u *articleUsecase) CreateArticle(authorID string, req *payload.CreateArticleRequest) (*payload.ArticleInfo, error) {
-	// check if author exist and login
-	author, err := u.Repo.User.SelectByID(authorID)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, err
+// position in an article feed, handed to the client as a signed cursor
+type articleCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int       `json:"id"`
+	Sort      string    `json:"sort"`
+	Before    bool      `json:"before,omitempty"`
+}
+
+// one cached page of an article listing
+type articleListCache struct {
+	Data []*payload.ArticleInfo `json:"data"`
+	Meta *common.Pagination     `json:"meta"`
+}
+
+func getArticleFilter(query *payload.ArticleQuery) *repository.ArticleFilter {
+	filter := &repository.ArticleFilter{}
+	if query == nil {
+		return filter
 	}
-	if author == nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_FOUND)
+
+	filter.Tags = models.NormalizeTagNames(query.Tags)
+	filter.MatchAllTags = query.TagMode != payload.TAG_MODE_OR
+	filter.Sort = query.Sort
+	return filter
+}
+
+// delete all cache about article
+func (u *articleUsecase) clearArticleCache(ctx context.Context, id int, authorID string) {
+	u.Cache.Delete(ctx, CACHE_ARTICLE_BY_ID+strconv.Itoa(id))
+	u.clearListCache(ctx, CACHE_ARTICLES_BY_AUTHOR_ID+authorID)
+	u.clearListCache(ctx, CACHE_ALL_ARTICLES)
+}
+
+// delete every cached article and listing, returns how many keys were deleted
+func (u *articleUsecase) flushArticleCache(ctx context.Context) (int, error) {
+	deleted := 0
+	for _, prefix := range []string{CACHE_ARTICLE_BY_ID, CACHE_ARTICLES_BY_AUTHOR_ID, CACHE_ALL_ARTICLES} {
+		n, err := u.Cache.DeleteByPrefix(ctx, prefix)
+		deleted += int(n)
+		if err != nil {
+			return deleted, err
+		}
 	}
+	return deleted, nil
+}
 
-	// check in redis if user is logged in
-	_, err = u.RedisClient.Get(authorID).Result()
-	if err != nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
+func (u *articleUsecase) clearListCache(ctx context.Context, group string) {
+	keys, _ := u.Cache.SetMembers(ctx, group+CACHE_KEYS_SUFFIX)
+	keys = append(keys, group+CACHE_KEYS_SUFFIX)
+	u.Cache.Delete(ctx, keys...)
+}
+
+// load a page of articles, the page is cached under the group unless the group is empty
+func (u *articleUsecase) listArticles(ctx context.Context, group string, filter *repository.ArticleFilter, pagination *common.PaginationRequest, load func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	if pagination.CursorMode {
+		return u.listArticlesByCursor(ctx, filter, pagination, load)
 	}
 
-	// using createtx
-	article := &models.ArticleModel{
-		Title:    req.Title,
-		Body:     req.Content,
-		AuthorID: authorID,
+	// listings filtered by tag are not cached
+	if len(filter.Tags) > 0 {
+		group = ""
 	}
 
-	u.RedisClient.Del("GET_ALL_ARTICLES")
+	key := fmt.Sprintf("%s_%s_%d_%d", group, filter.Sort, pagination.Page, pagination.PerPage)
 
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
-		createdArticle, err := u.Repo.Article.CreateTx(tx, article)
-		if err != nil {
-			return err
+	if group != "" {
+		data, err := u.Cache.Get(ctx, key)
+		if err != nil && err != cache.ErrMiss {
+			return nil, nil, apperror.Internal(err)
 		}
 
-		article = createdArticle
-		return nil
-	})
+		if data != "" {
+			cached := &articleListCache{}
+			err := json.Unmarshal([]byte(data), cached)
+			if err != nil {
+				return nil, nil, apperror.Internal(err)
+			}
+			return cached.Data, cached.Meta, nil
+		}
+	}
+
+	articles, total, err := load(pagination)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return article.PublicInfo(), nil
-}
+	res := articlesPublicInfo(articles)
+	meta := utils.CalculateMetaPagination(total, pagination)
+
+	if group != "" {
+		dataJsonByte, err := json.Marshal(&articleListCache{Data: res, Meta: meta})
+		if err != nil {
+			return nil, nil, apperror.Internal(err)
+		}
 
-func (u *articleUsecase) GetAllArticles() ([]*payload.ArticleInfo, error) {
-	data, err := u.RedisClient.Get("GET_ALL_ARTICLES").Result()
-	if err != nil && err != redis.Nil {
-		return nil, errors.New(payload.ERROR_GET_ARTICLE)
+		u.Cache.Set(ctx, key, string(dataJsonByte), 0)
+		u.Cache.AddToSet(ctx, group+CACHE_KEYS_SUFFIX, key)
 	}
 
-	var res []*payload.ArticleInfo
+	return res, meta, nil
+}
 
-	if data != "" {
-		err := json.Unmarshal([]byte(data), &res)
+// load a keyset page of articles, these pages are never cached because the
+// cursors point into a listing that keeps changing
+func (u *articleUsecase) listArticlesByCursor(ctx context.Context, filter *repository.ArticleFilter, pagination *common.PaginationRequest, load func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	if filter.Sort != "" && filter.Sort != "created_at" && filter.Sort != DEFAULT_CURSOR_SORT {
+		return nil, nil, payload.ErrCursorSort
+	}
+
+	if pagination.Cursor != "" {
+		cursor := &articleCursor{}
+		err := utils.DecodeCursor(pagination.Cursor, u.ServerInfo.CursorSecret, cursor)
 		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+			return nil, nil, payload.ErrCursorInvalid
 		}
 
-		return res, nil
-	} else {
-		articles, err := u.Repo.Article.GetAll()
-		if err != nil {
-			return nil, err
+		// the sort is kept in the cursor so every page of the feed uses the same order
+		filter.Sort = cursor.Sort
+		filter.Cursor = &repository.ArticleCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Before: cursor.Before}
+	}
+	if filter.Sort == "" {
+		filter.Sort = DEFAULT_CURSOR_SORT
+	}
+
+	// one more article is loaded to know if there is another page after this one
+	page := *pagination
+	page.Limit = pagination.PerPage + 1
+
+	articles, _, err := load(&page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := int64(len(articles)) > pagination.PerPage
+	if hasMore {
+		articles = articles[:pagination.PerPage]
+	}
+
+	before := filter.Cursor != nil && filter.Cursor.Before
+	if before {
+		// the page before the cursor was loaded in the reverse order
+		for i, j := 0, len(articles)-1; i < j; i, j = i+1, j-1 {
+			articles[i], articles[j] = articles[j], articles[i]
+		}
+	}
+
+	meta := &common.Pagination{PerPage: pagination.PerPage}
+
+	if len(articles) > 0 {
+		hasNext := hasMore
+		hasPrev := filter.Cursor != nil
+		if before {
+			hasNext, hasPrev = true, hasMore
 		}
 
-		res = make([]*payload.ArticleInfo, 0)
-		for _, article := range articles {
-			res = append(res, article.PublicInfo())
+		if hasNext {
+			last := articles[len(articles)-1]
+			meta.NextCursor, err = utils.EncodeCursor(&articleCursor{CreatedAt: last.CreatedAt, ID: last.ID, Sort: filter.Sort}, u.ServerInfo.CursorSecret)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 
-		dataJsonByte, err := json.Marshal(res)
-		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+		if hasPrev {
+			first := articles[0]
+			meta.PrevCursor, err = utils.EncodeCursor(&articleCursor{CreatedAt: first.CreatedAt, ID: first.ID, Sort: filter.Sort, Before: true}, u.ServerInfo.CursorSecret)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
-		dataJson := string(dataJsonByte)
 
-		u.RedisClient.Set("GET_ALL_ARTICLES", dataJson, 0)
+		meta.IsLoadMore = hasNext
 	}
 
-	return res, nil
+	return articlesPublicInfo(articles), meta, nil
 }
 
-func (u *articleUsecase) GetArticleByID(id int) (*payload.ArticleInfo, error) {
-	data, err := u.RedisClient.Get("GET_ARTICLE_BY_ID_" + strconv.Itoa(id)).Result()
-	if err != nil && err != redis.Nil {
-		return nil, errors.New(payload.ERROR_GET_ARTICLE)
+func (u *articleUsecase) CreateArticle(ctx context.Context, authorID string, req *payload.CreateArticleRequest) (*payload.ArticleInfo, error) {
+	// check if author exist and login
+	author, err := u.Repo.User.SelectByID(ctx, authorID)
+	if err != nil {
+		return nil, err
 	}
 
-	var res *payload.ArticleInfo
+	if !policy.CanWriteArticle(author) {
+		return nil, payload.ErrPermissionDenied
+	}
 
-	if data != "" {
-		err := json.Unmarshal([]byte(data), &res)
+	if u.ServerInfo.RequireEmailVerification && !author.IsEmailVerified() {
+		return nil, payload.ErrEmailNotVerified
+	}
+
+	// using createtx
+	article := &models.ArticleModel{
+		Title:    req.Title,
+		Body:     req.Content,
+		Status:   req.Status,
+		AuthorID: authorID,
+	}
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		createdArticle, err := u.Repo.Article.Create(ctx, article)
 		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+			return err
 		}
-	} else {
-		article, err := u.Repo.Article.SelectByID(id)
+
+		_, err = u.Repo.ArticleRevision.Create(ctx, &models.ArticleRevisionModel{
+			ArticleID: createdArticle.ID,
+			Title:     createdArticle.Title,
+			Body:      createdArticle.Body,
+			EditorID:  authorID,
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		res = article.PublicInfo()
+		tags, err := u.Repo.Tag.SelectOrCreateByNames(ctx, models.NormalizeTagNames(req.Tags))
+		if err != nil {
+			return err
+		}
 
-		dataJsonByte, err := json.Marshal(res)
+		err = u.Repo.Article.ReplaceTags(ctx, createdArticle, tags)
 		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+			return err
 		}
-		dataJson := string(dataJsonByte)
-		u.RedisClient.Set("GET_ARTICLE_BY_ID_"+strconv.Itoa(id), dataJson, 0)
+
+		article = createdArticle
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return res, nil
+	if article.IsPublished() {
+		u.clearArticleCache(ctx, article.ID, authorID)
+	}
+	u.syncSearchIndex(ctx, article)
+
+	article.Author = author
+
+	return article.PublicInfo(), nil
 }
 
-func (u *articleUsecase) GetArticlesByAuthorID(authorID string) ([]*payload.ArticleInfo, error) {
-	data, err := u.RedisClient.Get("GET_ARTICLES_BY_AUTHOR_ID_" + authorID).Result()
-	if err != nil && err != redis.Nil {
-		return nil, errors.New(payload.ERROR_GET_ARTICLE)
+func (u *articleUsecase) GetAllArticles(ctx context.Context, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	filter := getArticleFilter(query)
+	return u.listArticles(ctx, CACHE_ALL_ARTICLES, filter, pagination, func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+		return u.Repo.Article.GetAll(ctx, filter, pagination)
+	})
+}
+
+func (u *articleUsecase) GetArticleByID(ctx context.Context, id int) (*payload.ArticleInfo, error) {
+	data, err := u.Cache.Get(ctx, CACHE_ARTICLE_BY_ID+strconv.Itoa(id))
+	if err != nil && err != cache.ErrMiss {
+		return nil, apperror.Internal(err)
 	}
 
-	var res []*payload.ArticleInfo
+	var res *payload.ArticleInfo
 
 	if data != "" {
 		err := json.Unmarshal([]byte(data), &res)
 		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+			return nil, apperror.Internal(err)
 		}
 	} else {
-		articles, err := u.Repo.Article.SelectByAuthorID(authorID)
+		article, err := u.Repo.Article.SelectByID(ctx, id)
 		if err != nil {
 			return nil, err
 		}
 
-		res = make([]*payload.ArticleInfo, 0)
-		for _, article := range articles {
-			res = append(res, article.PublicInfo())
+		// only published articles are visible to readers
+		if !article.IsPublished() {
+			return nil, payload.ErrArticleNotFound
 		}
 
+		res = article.PublicInfo()
+
 		dataJsonByte, err := json.Marshal(res)
 		if err != nil {
-			return nil, errors.New(payload.ERROR_GET_ARTICLE)
+			return nil, apperror.Internal(err)
 		}
 		dataJson := string(dataJsonByte)
-		u.RedisClient.Set("GET_ARTICLES_BY_AUTHOR_ID_"+authorID, dataJson, 0)
+		u.Cache.Set(ctx, CACHE_ARTICLE_BY_ID+strconv.Itoa(id), dataJson, 0)
 	}
 
 	return res, nil
 }
 
-func (u *articleUsecase) SearchArticlesByTitleAndContent(content string) ([]*payload.ArticleInfo, error) {
-	articles, err := u.Repo.Article.SearchByTitleAndContent(content)
-	if err != nil {
-		return nil, err
-	}
-
-	res := make([]*payload.ArticleInfo, 0)
-	for _, article := range articles {
-		res = append(res, article.PublicInfo())
-	}
+func (u *articleUsecase) GetArticlesByAuthorID(ctx context.Context, authorID string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	filter := getArticleFilter(query)
+	return u.listArticles(ctx, CACHE_ARTICLES_BY_AUTHOR_ID+authorID, filter, pagination, func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+		return u.Repo.Article.SelectByAuthorID(ctx, authorID, filter, pagination)
+	})
+}
 
-	return res, nil
+func (u *articleUsecase) SearchArticlesByTitleAndContent(ctx context.Context, content string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	filter := getArticleFilter(query)
+	return u.searchArticles(ctx, content, filter, pagination, func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+		return u.Repo.Article.GetAll(ctx, filter, pagination)
+	})
 }
 
-func (u *articleUsecase) DeleteArticleByID(id int, authorId string) error {
-	// check if article is owned by author
-	article, err := u.Repo.Article.SelectByID(id)
+func (u *articleUsecase) DeleteArticleByID(ctx context.Context, id int, userID string) error {
+	actor, err := getActor(ctx, u.Repo, userID)
 	if err != nil {
 		return err
 	}
 
-	if article.AuthorID != authorId {
-		return errors.New(payload.ERROR_ARTICLE_NOT_ALLOWED)
+	article, err := u.Repo.Article.SelectByID(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	// check in redis if user is logged in
-	_, err = u.RedisClient.Get(authorId).Result()
-	if err != nil {
-		return errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
+	if !policy.CanDeleteArticle(actor, article) {
+		return payload.ErrArticleNotAllowed
 	}
 
-	// delete all redis cache about article
-	u.RedisClient.Del("GET_ARTICLE_BY_ID_" + strconv.Itoa(id))
-	u.RedisClient.Del("GET_ARTICLES_BY_AUTHOR_ID_" + authorId)
-	u.RedisClient.Del("GET_ALL_ARTICLES")
+	u.clearArticleCache(ctx, id, article.AuthorID)
 
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
 		article := &models.ArticleModel{
 			ID: id,
 		}
-		err := u.Repo.Article.DeleteTx(tx, article)
+		err := u.Repo.Article.Delete(ctx, article)
+		if err != nil {
+			return err
+		}
+
+		// comments go away together with the article
+		err = u.Repo.Comment.DeleteByArticleID(ctx, id)
 		if err != nil {
 			return err
 		}
 
 		return nil
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	u.removeFromSearchIndex(ctx, id)
+	return nil
 }
 
-func (u *articleUsecase) UpdateArticleByID(id int, req *payload.UpdateArticleRequest, authorId string) (*payload.ArticleInfo, error) {
-	// check if author exist and login
-	author, err := u.Repo.User.SelectByID(authorId)
-	if err != nil && err != gorm.ErrRecordNotFound {
+func (u *articleUsecase) UpdateArticleByID(ctx context.Context, id int, req *payload.UpdateArticleRequest, userID string) (*payload.ArticleInfo, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
 		return nil, err
 	}
-	if author == nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_FOUND)
-	}
 
-	// check in redis if user is logged in
-	_, err = u.RedisClient.Get(authorId).Result()
+	article, err := u.Repo.Article.SelectByID(ctx, id)
 	if err != nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
+		return nil, err
 	}
 
-	// check if article is owned by author
-	article, err := u.Repo.Article.SelectByID(id)
-	if err != nil {
-		return nil, err
+	if !policy.CanEditArticle(actor, article) {
+		return nil, payload.ErrArticleNotAllowed
 	}
 
+	author := article.Author
 	article.Author = nil
 
-	if article.AuthorID != authorId {
-		return nil, errors.New(payload.ERROR_ARTICLE_NOT_ALLOWED)
-	}
-
-	// delete all redis cache about article
-	u.RedisClient.Del("GET_ARTICLE_BY_ID_" + strconv.Itoa(id))
-	u.RedisClient.Del("GET_ARTICLES_BY_AUTHOR_ID_" + authorId)
-	u.RedisClient.Del("GET_ALL_ARTICLES")
+	u.clearArticleCache(ctx, id, article.AuthorID)
 
 	if req.Title != "" {
 		article.Title = req.Title
@@ -261,8 +427,31 @@ func (u *articleUsecase) UpdateArticleByID(id int, req *payload.UpdateArticleReq
 		article.Body = req.Content
 	}
 
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
-		err := u.Repo.Article.UpdateTx(tx, article)
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		err := u.Repo.Article.Update(ctx, article)
+		if err != nil {
+			return err
+		}
+
+		if req.Tags != nil {
+			tags, err := u.Repo.Tag.SelectOrCreateByNames(ctx, models.NormalizeTagNames(*req.Tags))
+			if err != nil {
+				return err
+			}
+
+			err = u.Repo.Article.ReplaceTags(ctx, article, tags)
+			if err != nil {
+				return err
+			}
+		}
+
+		// keep the edited content as a new revision
+		_, err = u.Repo.ArticleRevision.Create(ctx, &models.ArticleRevisionModel{
+			ArticleID: article.ID,
+			Title:     article.Title,
+			Body:      article.Body,
+			EditorID:  actor.ID,
+		})
 		if err != nil {
 			return err
 		}
@@ -273,21 +462,214 @@ func (u *articleUsecase) UpdateArticleByID(id int, req *payload.UpdateArticleReq
 		return nil, err
 	}
 
+	u.syncSearchIndex(ctx, article)
+
 	article.Author = author
 
 	return article.PublicInfo(), nil
 }
 
-func (u *articleUsecase) GetArticleSearchAndByAuthorID(authorID string, content string) ([]*payload.ArticleInfo, error) {
-	articles, err := u.Repo.Article.SearchByTitleAndContentAndAuthorID(authorID, content)
+func (u *articleUsecase) GetArticleSearchAndByAuthorID(ctx context.Context, authorID string, content string, query *payload.ArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	filter := getArticleFilter(query)
+	return u.searchArticles(ctx, content, filter, pagination, func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+		return u.Repo.Article.SelectByAuthorID(ctx, authorID, filter, pagination)
+	})
+}
+
+// find the hits in the search index then list the matching articles in the order of relevance
+func (u *articleUsecase) searchArticles(ctx context.Context, content string, filter *repository.ArticleFilter, pagination *common.PaginationRequest, load func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error)) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	query := search.ParseQuery(content)
+	if query.IsEmpty() {
+		return nil, nil, payload.ErrSearchQueryEmpty
+	}
+
+	hits, err := u.Search.Search(ctx, query, SEARCH_MAX_HITS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter.IDs = make([]int, 0, len(hits))
+	for _, hit := range hits {
+		filter.IDs = append(filter.IDs, hit.ID)
+	}
+
+	res, meta, err := u.listArticles(ctx, "", filter, pagination, load)
+	if err != nil {
+		return nil, nil, err
+	}
+	return searchResults(query, res), meta, nil
+}
+
+// rebuild the search index from every published article, returns how many were indexed
+func (u *articleUsecase) ReindexSearch(ctx context.Context) (int, error) {
+	docs := []*search.Document{}
+	for offset := 0; ; offset += SEARCH_REINDEX_BATCH_SIZE {
+		articles, _, err := u.Repo.Article.GetAll(ctx, nil, &common.PaginationRequest{Limit: SEARCH_REINDEX_BATCH_SIZE, Offset: int64(offset)})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, article := range articles {
+			docs = append(docs, articleDocument(article))
+		}
+
+		if len(articles) < SEARCH_REINDEX_BATCH_SIZE {
+			break
+		}
+	}
+
+	err := u.Search.Rebuild(ctx, docs)
+	if err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+// keep the search index in sync after an article changed, only published articles
+// can be found, a failure is only logged because the index can be rebuilt
+func (u *articleUsecase) syncSearchIndex(ctx context.Context, article *models.ArticleModel) {
+	var err error
+	if article.IsPublished() {
+		err = u.Search.Index(ctx, articleDocument(article))
+	} else {
+		err = u.Search.Delete(ctx, article.ID)
+	}
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error updating the search index for article %d: %v.", article.ID, err)
+	}
+}
+
+func (u *articleUsecase) removeFromSearchIndex(ctx context.Context, id int) {
+	err := u.Search.Delete(ctx, id)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error removing article %d from the search index: %v.", id, err)
+	}
+}
+
+func articleDocument(article *models.ArticleModel) *search.Document {
+	return &search.Document{ID: article.ID, Title: article.Title, Body: article.Body}
+}
+
+func (u *articleUsecase) GetMyArticles(ctx context.Context, authorID string, query *payload.MyArticleQuery, pagination *common.PaginationRequest) ([]*payload.ArticleInfo, *common.Pagination, error) {
+	filter := &repository.ArticleFilter{Sort: query.Sort}
+	return u.listArticles(ctx, "", filter, pagination, func(pagination *common.PaginationRequest) ([]*models.ArticleModel, int64, error) {
+		return u.Repo.Article.SelectByAuthorIDAndStatus(ctx, authorID, query.Status, filter, pagination)
+	})
+}
+
+func (u *articleUsecase) GetMyArticleByID(ctx context.Context, id int, authorID string) (*payload.ArticleInfo, error) {
+	article, err := u.Repo.Article.SelectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if article.AuthorID != authorID {
+		return nil, payload.ErrArticleNotAllowed
+	}
+
+	return article.PublicInfo(), nil
+}
+
+func (u *articleUsecase) PublishArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error) {
+	return u.changeArticleStatus(ctx, id, userID, models.ARTICLE_STATUS_PUBLISHED)
+}
+
+func (u *articleUsecase) UnpublishArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error) {
+	return u.changeArticleStatus(ctx, id, userID, models.ARTICLE_STATUS_DRAFT)
+}
+
+func (u *articleUsecase) ArchiveArticle(ctx context.Context, id int, userID string) (*payload.ArticleInfo, error) {
+	return u.changeArticleStatus(ctx, id, userID, models.ARTICLE_STATUS_ARCHIVED)
+}
+
+func (u *articleUsecase) ScheduleArticle(ctx context.Context, id int, req *payload.ScheduleArticleRequest, userID string) (*payload.ArticleInfo, error) {
+	return u.updateArticleStatus(ctx, id, userID, func(article *models.ArticleModel) error {
+		return article.Schedule(req.PublishAt)
+	})
+}
+
+// publish every scheduled article that is due, returns how many were published
+func (u *articleUsecase) PublishScheduledArticles(ctx context.Context, limit int) (int, error) {
+	var published []*models.ArticleModel
+
+	err := u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		articles, err := u.Repo.Article.PublishScheduled(ctx, time.Now(), limit)
+		if err != nil {
+			return err
+		}
+
+		published = articles
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, article := range published {
+		u.clearArticleCache(ctx, article.ID, article.AuthorID)
+		u.syncSearchIndex(ctx, article)
+	}
+
+	return len(published), nil
+}
+
+func (u *articleUsecase) changeArticleStatus(ctx context.Context, id int, userID string, status string) (*payload.ArticleInfo, error) {
+	return u.updateArticleStatus(ctx, id, userID, func(article *models.ArticleModel) error {
+		return article.TransitionTo(status)
+	})
+}
+
+func (u *articleUsecase) updateArticleStatus(ctx context.Context, id int, userID string, change func(article *models.ArticleModel) error) (*payload.ArticleInfo, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := u.Repo.Article.SelectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanEditArticle(actor, article) {
+		return nil, payload.ErrArticleNotAllowed
+	}
+
+	err = change(article)
+	if err != nil {
+		return nil, err
+	}
+
+	author := article.Author
+	article.Author = nil
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.Article.Update(ctx, article)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	u.clearArticleCache(ctx, id, article.AuthorID)
+	u.syncSearchIndex(ctx, article)
+
+	article.Author = author
+
+	return article.PublicInfo(), nil
+}
+
+// search results show a highlighted snippet around the match instead of the whole content
+func searchResults(query *search.Query, articles []*payload.ArticleInfo) []*payload.ArticleInfo {
+	for _, article := range articles {
+		article.Snippet = query.Highlight(article.Content, SEARCH_SNIPPET_SIZE)
+		article.Content = ""
+	}
+	return articles
+}
+
+func articlesPublicInfo(articles []*models.ArticleModel) []*payload.ArticleInfo {
 	res := make([]*payload.ArticleInfo, 0)
 	for _, article := range articles {
 		res = append(res, article.PublicInfo())
 	}
-
-	return res, nil
+	return res
 }
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/policy"
+	"github.com/haikalvidya/go-article/pkg/utils"
+)
+
+type IArticleRevisionUsecase interface {
+	GetRevisions(ctx context.Context, articleID int, authorID string) ([]*payload.ArticleRevisionInfo, error)
+	GetRevision(ctx context.Context, articleID int, revision int, authorID string) (*payload.ArticleRevisionInfo, error)
+	DiffRevisions(ctx context.Context, articleID int, from int, to int, authorID string) (*payload.ArticleRevisionDiff, error)
+	RestoreRevision(ctx context.Context, articleID int, revision int, authorID string) (*payload.ArticleInfo, error)
+}
+
+type articleRevisionUsecase usecaseType
+
+// revisions can contain unpublished content so only the people who can edit the article see them
+func (u *articleRevisionUsecase) getOwnedArticle(ctx context.Context, articleID int, userID string) (*models.ArticleModel, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := u.Repo.Article.SelectByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanViewUnpublishedArticle(actor, article) {
+		return nil, payload.ErrArticleNotAllowed
+	}
+
+	return article, nil
+}
+
+func (u *articleRevisionUsecase) GetRevisions(ctx context.Context, articleID int, authorID string) ([]*payload.ArticleRevisionInfo, error) {
+	_, err := u.getOwnedArticle(ctx, articleID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := u.Repo.ArticleRevision.SelectByArticleID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*payload.ArticleRevisionInfo, 0)
+	for _, revision := range revisions {
+		res = append(res, revision.PublicInfo())
+	}
+
+	return res, nil
+}
+
+func (u *articleRevisionUsecase) GetRevision(ctx context.Context, articleID int, revision int, authorID string) (*payload.ArticleRevisionInfo, error) {
+	_, err := u.getOwnedArticle(ctx, articleID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	articleRevision, err := u.Repo.ArticleRevision.SelectByArticleIDAndRevision(ctx, articleID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return articleRevision.PublicInfo(), nil
+}
+
+func (u *articleRevisionUsecase) DiffRevisions(ctx context.Context, articleID int, from int, to int, authorID string) (*payload.ArticleRevisionDiff, error) {
+	_, err := u.getOwnedArticle(ctx, articleID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := u.Repo.ArticleRevision.SelectByArticleIDAndRevision(ctx, articleID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := u.Repo.ArticleRevision.SelectByArticleIDAndRevision(ctx, articleID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := diffLines(fromRevision.Title, toRevision.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := diffLines(fromRevision.Body, toRevision.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.ArticleRevisionDiff{
+		ArticleID: articleID,
+		From:      from,
+		To:        to,
+		Title:     title,
+		Content:   content,
+	}, nil
+}
+
+// restore the content of an old revision, it is saved as a new revision so nothing is lost
+func (u *articleRevisionUsecase) RestoreRevision(ctx context.Context, articleID int, revision int, userID string) (*payload.ArticleInfo, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := u.Repo.Article.SelectByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanEditArticle(actor, article) {
+		return nil, payload.ErrArticleNotAllowed
+	}
+
+	articleRevision, err := u.Repo.ArticleRevision.SelectByArticleIDAndRevision(ctx, articleID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	author := article.Author
+	article.Author = nil
+	article.Title = articleRevision.Title
+	article.Body = articleRevision.Body
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		err := u.Repo.Article.Update(ctx, article)
+		if err != nil {
+			return err
+		}
+
+		_, err = u.Repo.ArticleRevision.Create(ctx, &models.ArticleRevisionModel{
+			ArticleID:    article.ID,
+			Title:        article.Title,
+			Body:         article.Body,
+			EditorID:     actor.ID,
+			RestoredFrom: &articleRevision.Revision,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	(*articleUsecase)(u).clearArticleCache(ctx, article.ID, article.AuthorID)
+	(*articleUsecase)(u).syncSearchIndex(ctx, article)
+
+	article.Author = author
+
+	return article.PublicInfo(), nil
+}
+
+func diffLines(from string, to string) ([]*payload.DiffLine, error) {
+	lines, err := utils.LineDiff(from, to)
+	if errors.Is(err, utils.ErrDiffTooLarge) {
+		return nil, payload.ErrRevisionDiffTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*payload.DiffLine, 0)
+	for _, line := range lines {
+		res = append(res, &payload.DiffLine{
+			Op:   line.Op,
+			Text: line.Text,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/policy"
+)
+
+type ICommentUsecase interface {
+	GetComments(ctx context.Context, articleID int) ([]*payload.CommentInfo, error)
+	CreateComment(ctx context.Context, articleID int, authorID string, req *payload.CreateCommentRequest) (*payload.CommentInfo, error)
+	UpdateComment(ctx context.Context, articleID int, commentID int, userID string, req *payload.UpdateCommentRequest) (*payload.CommentInfo, error)
+	DeleteComment(ctx context.Context, articleID int, commentID int, userID string) error
+	HideComment(ctx context.Context, articleID int, commentID int, userID string) (*payload.CommentInfo, error)
+	UnhideComment(ctx context.Context, articleID int, commentID int, userID string) (*payload.CommentInfo, error)
+}
+
+type commentUsecase usecaseType
+
+// comments are only available on published articles
+func (u *commentUsecase) getPublishedArticle(ctx context.Context, articleID int) (*models.ArticleModel, error) {
+	article, err := u.Repo.Article.SelectByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if !article.IsPublished() {
+		return nil, payload.ErrArticleNotFound
+	}
+	return article, nil
+}
+
+func (u *commentUsecase) getArticleComment(ctx context.Context, articleID int, commentID int) (*models.CommentModel, error) {
+	comment, err := u.Repo.Comment.SelectByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.ArticleID != articleID {
+		return nil, payload.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// the comments are returned as a tree, hidden or deleted comments without replies are left out
+func (u *commentUsecase) GetComments(ctx context.Context, articleID int) ([]*payload.CommentInfo, error) {
+	_, err := u.getPublishedArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := u.Repo.Comment.SelectByArticleID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*payload.CommentInfo, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = comment.PublicInfo()
+	}
+
+	roots := make([]*payload.CommentInfo, 0)
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+
+		if parent, ok := nodes[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	return pruneComments(roots), nil
+}
+
+func pruneComments(comments []*payload.CommentInfo) []*payload.CommentInfo {
+	res := make([]*payload.CommentInfo, 0, len(comments))
+	for _, comment := range comments {
+		comment.Replies = pruneComments(comment.Replies)
+		if (comment.Hidden || comment.Deleted) && len(comment.Replies) == 0 {
+			continue
+		}
+		res = append(res, comment)
+	}
+	return res
+}
+
+func (u *commentUsecase) CreateComment(ctx context.Context, articleID int, authorID string, req *payload.CreateCommentRequest) (*payload.CommentInfo, error) {
+	article, err := u.getPublishedArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := u.Repo.User.SelectByID(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		_, err := u.getArticleComment(ctx, articleID, *req.ParentID)
+		if err != nil {
+			return nil, payload.ErrCommentParent
+		}
+	}
+
+	comment := &models.CommentModel{
+		ArticleID: articleID,
+		AuthorID:  authorID,
+		ParentID:  req.ParentID,
+		Body:      req.Content,
+	}
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		_, err := u.Repo.Comment.Create(ctx, comment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	(*articleUsecase)(u).clearArticleCache(ctx, articleID, article.AuthorID)
+
+	comment.Author = author
+
+	return comment.PublicInfo(), nil
+}
+
+func (u *commentUsecase) UpdateComment(ctx context.Context, articleID int, commentID int, userID string, req *payload.UpdateCommentRequest) (*payload.CommentInfo, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := u.getArticleComment(ctx, articleID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanEditComment(actor, comment) {
+		return nil, payload.ErrCommentNotAllowed
+	}
+
+	comment.Body = req.Content
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.Comment.Update(ctx, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment.PublicInfo(), nil
+}
+
+func (u *commentUsecase) DeleteComment(ctx context.Context, articleID int, commentID int, userID string) error {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := u.getArticleComment(ctx, articleID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if !policy.CanDeleteComment(actor, comment) {
+		return payload.ErrCommentNotAllowed
+	}
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.Comment.Delete(ctx, comment)
+	})
+	if err != nil {
+		return err
+	}
+
+	u.clearArticleCacheByID(ctx, articleID)
+
+	return nil
+}
+
+func (u *commentUsecase) HideComment(ctx context.Context, articleID int, commentID int, userID string) (*payload.CommentInfo, error) {
+	now := time.Now()
+	return u.setCommentHidden(ctx, articleID, commentID, userID, &now)
+}
+
+func (u *commentUsecase) UnhideComment(ctx context.Context, articleID int, commentID int, userID string) (*payload.CommentInfo, error) {
+	return u.setCommentHidden(ctx, articleID, commentID, userID, nil)
+}
+
+// the author of the article and the moderators can hide the comments on it
+func (u *commentUsecase) setCommentHidden(ctx context.Context, articleID int, commentID int, userID string, hiddenAt *time.Time) (*payload.CommentInfo, error) {
+	actor, err := getActor(ctx, u.Repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := u.Repo.Article.SelectByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanModerateComments(actor, article) {
+		return nil, payload.ErrArticleNotAllowed
+	}
+
+	comment, err := u.getArticleComment(ctx, articleID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.HiddenAt = hiddenAt
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.Comment.Update(ctx, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	(*articleUsecase)(u).clearArticleCache(ctx, articleID, article.AuthorID)
+
+	return comment.PublicInfo(), nil
+}
+
+func (u *commentUsecase) clearArticleCacheByID(ctx context.Context, articleID int) {
+	article, err := u.Repo.Article.SelectByID(ctx, articleID)
+	if err != nil {
+		return
+	}
+	(*articleUsecase)(u).clearArticleCache(ctx, articleID, article.AuthorID)
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+	"github.com/haikalvidya/go-article/pkg/utils"
+)
+
+const (
+	EMAIL_VERIFICATION_EXPIRATION = 24 * time.Hour
+	// a user can ask for the verification email again after this
+	EMAIL_VERIFICATION_RESEND_INTERVAL = time.Minute
+)
+
+// the verification token is signed instead of stored, it is tied to the email
+// so a link sent to an old email stops working once the email changes
+type emailVerificationClaims struct {
+	UserID    string `json:"uid"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// failures are only logged, the user can ask for the email again
+func (u *userUsecase) sendVerificationEmail(ctx context.Context, user *models.UserModel) {
+	token, err := utils.EncodeSigned(&emailVerificationClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(EMAIL_VERIFICATION_EXPIRATION).Unix(),
+	}, u.ServerInfo.EmailVerificationSecret)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error creating the verification token for user %s: %v.", user.ID, err)
+		return
+	}
+
+	err = u.Mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email, it expires in %s.\n\n%s\n",
+			user.Name, EMAIL_VERIFICATION_EXPIRATION, linkWithToken(u.ServerInfo.EmailVerificationURL, token)),
+	})
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error sending the verification email to user %s: %v.", user.ID, err)
+	}
+}
+
+func (u *userUsecase) VerifyEmail(ctx context.Context, req *payload.VerifyEmailRequest) (*payload.UserInfo, error) {
+	claims := &emailVerificationClaims{}
+	err := utils.DecodeSigned(req.Token, u.ServerInfo.EmailVerificationSecret, claims)
+	if err != nil || time.Now().Unix() > claims.ExpiresAt {
+		return nil, payload.ErrVerifyTokenInvalid
+	}
+
+	user, err := u.Repo.User.SelectByID(ctx, claims.UserID)
+	if errors.Is(err, payload.ErrUserNotFound) {
+		return nil, payload.ErrVerifyTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Email != claims.Email {
+		return nil, payload.ErrVerifyTokenInvalid
+	}
+
+	if user.IsEmailVerified() {
+		return user.PublicInfo(), nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.User.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user.PublicInfo(), nil
+}
+
+func (u *userUsecase) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return payload.ErrEmailVerified
+	}
+
+	allowed, err := u.Repo.Throttle.Allow(ctx, "VERIFICATION_EMAIL_"+user.ID, EMAIL_VERIFICATION_RESEND_INTERVAL)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return payload.ErrVerifyThrottled
+	}
+
+	u.sendVerificationEmail(ctx, user)
+	return nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+)
+
+// IInternalUsecase is used by the ops tooling through the internal api,
+// the callers are trusted so there are no policy checks
+type IInternalUsecase interface {
+	GetUserByID(ctx context.Context, id string) (*payload.InternalUserInfo, error)
+	GetUserByEmail(ctx context.Context, email string) (*payload.InternalUserInfo, error)
+	LogoutUser(ctx context.Context, id string) error
+	BanUser(ctx context.Context, id string) (*payload.InternalUserInfo, error)
+	UnbanUser(ctx context.Context, id string) (*payload.InternalUserInfo, error)
+	TakeDownArticle(ctx context.Context, id int) (*payload.ArticleInfo, error)
+	FlushCache(ctx context.Context) (*payload.CacheFlushInfo, error)
+	GetStats(ctx context.Context) (*payload.StatsInfo, error)
+}
+
+type internalUsecase usecaseType
+
+func (u *internalUsecase) GetUserByID(ctx context.Context, id string) (*payload.InternalUserInfo, error) {
+	user, err := u.Repo.User.SelectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.userInfo(ctx, user)
+}
+
+func (u *internalUsecase) GetUserByEmail(ctx context.Context, email string) (*payload.InternalUserInfo, error) {
+	user, err := u.Repo.User.SelectByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.userInfo(ctx, user)
+}
+
+func (u *internalUsecase) userInfo(ctx context.Context, user *models.UserModel) (*payload.InternalUserInfo, error) {
+	sessions, err := u.Repo.Session.SelectByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.InternalInfo(len(sessions)), nil
+}
+
+// revoke every session of the user, the access tokens stop working right away
+func (u *internalUsecase) LogoutUser(ctx context.Context, id string) error {
+	_, err := u.Repo.User.SelectByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.Repo.Session.DeleteByUserID(ctx, id)
+}
+
+// a banned user is logged out and can not log in again until unbanned
+func (u *internalUsecase) BanUser(ctx context.Context, id string) (*payload.InternalUserInfo, error) {
+	now := time.Now()
+	user, err := u.setUserBanned(ctx, id, &now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.Repo.Session.DeleteByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.InternalInfo(0), nil
+}
+
+func (u *internalUsecase) UnbanUser(ctx context.Context, id string) (*payload.InternalUserInfo, error) {
+	user, err := u.setUserBanned(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.userInfo(ctx, user)
+}
+
+func (u *internalUsecase) setUserBanned(ctx context.Context, id string, bannedAt *time.Time) (*models.UserModel, error) {
+	user, err := u.Repo.User.SelectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// banning again keeps the time of the first ban
+	if user.IsBanned() && bannedAt != nil {
+		return user, nil
+	}
+
+	user.BannedAt = bannedAt
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.User.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// hide the article from everyone but its author, who can not publish it again
+func (u *internalUsecase) TakeDownArticle(ctx context.Context, id int) (*payload.ArticleInfo, error) {
+	article, err := u.Repo.Article.SelectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	article.TakeDown()
+
+	author := article.Author
+	article.Author = nil
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.Article.Update(ctx, article)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	articles := (*articleUsecase)(u)
+	articles.clearArticleCache(ctx, id, article.AuthorID)
+	articles.removeFromSearchIndex(ctx, id)
+
+	article.Author = author
+
+	return article.PublicInfo(), nil
+}
+
+func (u *internalUsecase) FlushCache(ctx context.Context) (*payload.CacheFlushInfo, error) {
+	deleted, err := (*articleUsecase)(u).flushArticleCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.CacheFlushInfo{DeletedKeys: deleted}, nil
+}
+
+func (u *internalUsecase) GetStats(ctx context.Context) (*payload.StatsInfo, error) {
+	usersByRole, err := u.Repo.User.CountByRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	banned, err := u.Repo.User.CountBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	articlesByStatus, err := u.Repo.Article.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := u.Repo.Comment.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.StatsInfo{
+		Users:    &payload.UserStats{Total: sum(usersByRole), Banned: banned, ByRole: usersByRole},
+		Articles: &payload.ArticleStats{Total: sum(articlesByStatus), ByStatus: articlesByStatus},
+		Comments: comments,
+	}, nil
+}
+
+func sum(counts map[string]int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+const (
+	// failed logins are counted over this window, it is as long as the longest
+	// lockout so the backoff keeps growing while an attack goes on
+	LOGIN_FAILURE_WINDOW = time.Hour
+	// failures allowed before the account or the ip gets locked
+	LOGIN_ACCOUNT_FREE_ATTEMPTS = 5
+	LOGIN_IP_FREE_ATTEMPTS      = 20
+	// the lockout doubles with every failure after the free attempts
+	LOGIN_LOCKOUT_BASE = time.Minute
+	LOGIN_LOCKOUT_MAX  = time.Hour
+)
+
+// used to compare the password against when the email is unknown so it takes as long
+const dummyPasswordHash = "$2a$10$AEWdv4/N7NNXHit0OnwFEenn.3PL.2pjkiJTP0c9vJMtUHtC5rYLe"
+
+func loginAccountKey(email string) string {
+	return "LOGIN_EMAIL_" + strings.ToLower(email)
+}
+
+func loginIPKey(ip string) string {
+	return "LOGIN_IP_" + ip
+}
+
+// the keys the login is throttled by, the ip is empty when the login does not
+// come from a request
+func loginKeys(email string, ip string) []string {
+	if ip == "" {
+		return []string{loginAccountKey(email)}
+	}
+	return []string{loginAccountKey(email), loginIPKey(ip)}
+}
+
+// the email is locked whether it has an account or not, so a lockout does not tell either
+func (u *userUsecase) checkLoginLock(ctx context.Context, email string, ip string) error {
+	for _, key := range loginKeys(email, ip) {
+		lockedFor, err := u.Repo.Throttle.LockedFor(ctx, key)
+		if err != nil {
+			return err
+		}
+		if lockedFor > 0 {
+			u.securityEvent(SECURITY_EVENT_LOGIN_BLOCKED, "email", email, "ip", ip, "locked_for", lockedFor.String())
+			return payload.ErrLoginLocked
+		}
+	}
+	return nil
+}
+
+func (u *userUsecase) recordLoginFailure(ctx context.Context, email string, ip string) {
+	u.securityEvent(SECURITY_EVENT_LOGIN_FAILED, "email", email, "ip", ip)
+	u.countLoginFailures(ctx, email, ip)
+}
+
+// count the failure for the account and the ip, a redis error is only logged
+// because the login already failed anyway. wrong two factor codes count too so
+// they can not be guessed by starting new challenges with a known password
+func (u *userUsecase) countLoginFailures(ctx context.Context, email string, ip string) {
+	u.countLoginFailure(ctx, loginAccountKey(email), LOGIN_ACCOUNT_FREE_ATTEMPTS, SECURITY_EVENT_ACCOUNT_LOCKED, email, ip)
+	if ip != "" {
+		u.countLoginFailure(ctx, loginIPKey(ip), LOGIN_IP_FREE_ATTEMPTS, SECURITY_EVENT_IP_LOCKED, email, ip)
+	}
+}
+
+func (u *userUsecase) countLoginFailure(ctx context.Context, key string, freeAttempts int64, event string, email string, ip string) {
+	failures, err := u.Repo.Throttle.Hit(ctx, key, LOGIN_FAILURE_WINDOW)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error counting the failed login for %s: %v.", key, err)
+		return
+	}
+	if failures <= freeAttempts {
+		return
+	}
+
+	duration := lockoutDuration(failures - freeAttempts)
+	err = u.Repo.Throttle.Lock(ctx, key, duration)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error locking the login for %s: %v.", key, err)
+		return
+	}
+
+	u.securityEvent(event, "email", email, "ip", ip, "failures", strconv.FormatInt(failures, 10), "locked_for", duration.String())
+}
+
+// the failures of the ip are kept, an attacker with one valid account should not reset them
+func (u *userUsecase) resetLoginFailures(ctx context.Context, email string) {
+	err := u.Repo.Throttle.Reset(ctx, loginAccountKey(email))
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error resetting the failed logins of %s: %v.", email, err)
+	}
+}
+
+// 1, 2, 4, 8 ... minutes for every failure over the free attempts, up to the max
+func lockoutDuration(excess int64) time.Duration {
+	if excess > 6 {
+		return LOGIN_LOCKOUT_MAX
+	}
+
+	duration := LOGIN_LOCKOUT_BASE << (excess - 1)
+	if duration > LOGIN_LOCKOUT_MAX {
+		return LOGIN_LOCKOUT_MAX
+	}
+	return duration
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		excess int64
+		want   time.Duration
+	}{
+		{excess: 1, want: time.Minute},
+		{excess: 2, want: 2 * time.Minute},
+		{excess: 3, want: 4 * time.Minute},
+		{excess: 4, want: 8 * time.Minute},
+		{excess: 5, want: 16 * time.Minute},
+		{excess: 6, want: 32 * time.Minute},
+		{excess: 7, want: time.Hour},
+		{excess: 8, want: time.Hour},
+		// large counts must not overflow the shift
+		{excess: 64, want: time.Hour},
+		{excess: 1000, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.excess); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, want %s", tt.excess, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/oidc"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	OIDC_STATE_SIZE = 32
+	// time the user has to log in at the provider
+	OIDC_LOGIN_EXPIRATION = 10 * time.Minute
+	// users made by the provider login, and unverified users taken over by it,
+	// get a random password nobody knows. they can set one with the password reset
+	OIDC_PASSWORD_SIZE = 32
+)
+
+// start the login with the provider, the state, nonce and pkce verifier are
+// kept server side until the user comes back
+func (u *userUsecase) StartOIDCLogin(ctx context.Context) (*payload.OIDCLoginResponse, error) {
+	if u.OIDC == nil {
+		return nil, payload.ErrOIDCNotConfigured
+	}
+
+	state, err := utils.GenerateRandomToken(OIDC_STATE_SIZE)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := u.OIDC.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error starting the oidc login: %v.", err)
+		return nil, payload.ErrOIDCFailed
+	}
+
+	login := &models.OIDCLoginModel{CodeVerifier: verifier, Nonce: nonce}
+	err = u.Repo.UserIdentity.CreateLogin(ctx, hashToken(state), login, OIDC_LOGIN_EXPIRATION)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.OIDCLoginResponse{AuthorizationURL: authURL}, nil
+}
+
+// finish the login with the code the provider sent back, the identity is linked
+// to the user with the same email when the provider verified it, a user is
+// made when there is none. see takeOverUnverifiedUser for users who never
+// verified their email
+func (u *userUsecase) LoginOIDC(ctx context.Context, req *payload.OIDCCallbackRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error) {
+	if u.OIDC == nil {
+		return nil, payload.ErrOIDCNotConfigured
+	}
+
+	login, err := u.Repo.UserIdentity.ConsumeLogin(ctx, hashToken(req.State))
+	if err == redis.Nil {
+		return nil, payload.ErrOIDCStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := u.OIDC.Exchange(ctx, req.Code, login.CodeVerifier)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error exchanging the oidc code: %v.", err)
+		return nil, payload.ErrOIDCFailed
+	}
+
+	idToken, err := u.OIDC.Verify(ctx, token.IDToken, login.Nonce)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error verifying the oidc id token: %v.", err)
+		return nil, payload.ErrOIDCFailed
+	}
+
+	user, err := u.userForIdentity(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsBanned() {
+		return nil, payload.ErrUserBanned
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return u.startTwoFactorChallenge(ctx, user)
+	}
+
+	tokens, err := u.createSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.UserWithTokenResponse{
+		UserInfo:     user.PublicInfo(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+func (u *userUsecase) userForIdentity(ctx context.Context, idToken *oidc.IDToken) (*models.UserModel, error) {
+	identity, err := u.Repo.UserIdentity.SelectByIssuerAndSubject(ctx, idToken.Issuer, idToken.Subject)
+	if err == nil {
+		return getActor(ctx, u.Repo, identity.UserID)
+	}
+	if !errors.Is(err, payload.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// an unverified email could belong to someone else, linking it would hand
+	// them the account
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, payload.ErrOIDCEmailNotVerified
+	}
+
+	user, err := u.Repo.User.SelectByEmail(ctx, idToken.Email)
+	if err != nil && !errors.Is(err, payload.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// the account was never proven to belong to the owner of the email
+	takenOver := user != nil && !user.IsEmailVerified()
+
+	now := time.Now()
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		if user == nil {
+			user, err = u.newOIDCUser(ctx, idToken)
+			if err != nil {
+				return err
+			}
+			user, err = u.Repo.User.Create(ctx, user)
+			if err != nil {
+				return err
+			}
+		} else if !user.IsEmailVerified() {
+			// the provider vouches for the email
+			user.EmailVerifiedAt = &now
+			err = u.takeOverUnverifiedUser(ctx, user)
+			if err != nil {
+				return err
+			}
+		}
+
+		return u.Repo.UserIdentity.Create(ctx, &models.UserIdentityModel{
+			UserID:  user.ID,
+			Issuer:  idToken.Issuer,
+			Subject: idToken.Subject,
+			Email:   idToken.Email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if takenOver {
+		// the sessions are in redis so they go once the link is saved
+		err = u.Repo.Session.DeleteByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u.securityEvent(SECURITY_EVENT_IDENTITY_LINKED, "user_id", user.ID, "issuer", idToken.Issuer, "subject", idToken.Subject)
+	return user, nil
+}
+
+// anyone can register with an email they do not own and wait for the owner to
+// log in with the provider. so the password, second factor and api keys of an
+// unverified user are dropped before the owner gets the account
+func (u *userUsecase) takeOverUnverifiedUser(ctx context.Context, user *models.UserModel) error {
+	password, err := randomPassword()
+	if err != nil {
+		return err
+	}
+	user.Password = password
+	user.TotpSecret = nil
+	user.TotpEnabledAt = nil
+
+	err = u.Repo.User.Update(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	err = u.Repo.RecoveryCode.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	keys, err := u.Repo.APIKey.SelectByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		_, err = u.Repo.APIKey.Delete(ctx, key.ID, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the hash of a random password nobody knows
+func randomPassword() (string, error) {
+	random, err := utils.GenerateRandomToken(OIDC_PASSWORD_SIZE)
+	if err != nil {
+		return "", err
+	}
+	password, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+func (u *userUsecase) newOIDCUser(ctx context.Context, idToken *oidc.IDToken) (*models.UserModel, error) {
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	name := idToken.Name
+	if name == "" {
+		name = idToken.Email
+	}
+
+	now := time.Now()
+	return &models.UserModel{
+		Email:           idToken.Email,
+		EmailVerifiedAt: &now,
+		Name:            name,
+		Password:        password,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PASSWORD_RESET_TOKEN_SIZE       = 32
+	PASSWORD_RESET_TOKEN_EXPIRATION = time.Hour
+	// the reset email is sent after the response, this bounds the saving of the
+	// token and the sending of the email
+	PASSWORD_RESET_EMAIL_TIMEOUT = 30 * time.Second
+)
+
+// email a password reset token to the user, unknown emails are accepted the same
+// way so the response does not tell which emails have an account. the token is
+// made and sent after the response so a known email does not take longer either
+func (u *userUsecase) ForgotPassword(ctx context.Context, req *payload.ForgotPasswordRequest) error {
+	user, err := u.Repo.User.SelectByEmail(ctx, req.Email)
+	if errors.Is(err, payload.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.IsBanned() {
+		return nil
+	}
+
+	go u.sendPasswordResetEmail(user)
+	return nil
+}
+
+// the request is over by now so it runs with its own context, failures are
+// only logged and the user can ask again
+func (u *userUsecase) sendPasswordResetEmail(user *models.UserModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), PASSWORD_RESET_EMAIL_TIMEOUT)
+	defer cancel()
+
+	token, err := utils.GenerateRandomToken(PASSWORD_RESET_TOKEN_SIZE)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error creating the password reset token for user %s: %v.", user.ID, err)
+		return
+	}
+
+	err = u.Repo.OneTimeToken.Create(ctx, repository.TOKEN_PURPOSE_PASSWORD_RESET, user.ID, hashToken(token), PASSWORD_RESET_TOKEN_EXPIRATION)
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error saving the password reset token for user %s: %v.", user.ID, err)
+		return
+	}
+
+	err = u.Mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password, it expires in %s.\n\n%s\n\nIf you did not ask for this you can ignore this email.\n",
+			user.Name, PASSWORD_RESET_TOKEN_EXPIRATION, linkWithToken(u.ServerInfo.PasswordResetURL, token)),
+	})
+	if err != nil {
+		u.Middleware.Logger.Errorf("Error sending the password reset email to user %s: %v.", user.ID, err)
+	}
+}
+
+// add the token to the link of the page that uses it, only the token is given
+// when the page is not configured
+func linkWithToken(base string, token string) string {
+	link, err := url.Parse(base)
+	if err != nil || base == "" {
+		return "Token: " + token
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// set the new password and log out every session, the token can only be used once
+func (u *userUsecase) ResetPassword(ctx context.Context, req *payload.ResetPasswordRequest) error {
+	if req.Password != req.PasswordConfirmation {
+		return payload.ErrPasswordNotMatch
+	}
+
+	userID, err := u.Repo.OneTimeToken.Consume(ctx, repository.TOKEN_PURPOSE_PASSWORD_RESET, hashToken(req.Token))
+	if err == redis.Nil {
+		return payload.ErrResetTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if errors.Is(err, payload.ErrUserNotFound) {
+		return payload.ErrResetTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	password, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	user.Password = string(password)
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.User.Update(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.Repo.Session.DeleteByUserID(ctx, user.ID)
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// security events are written to the log as key=value pairs so alerting can pick them up
+const (
+	SECURITY_EVENT_LOGIN_FAILED      = "login_failed"
+	SECURITY_EVENT_LOGIN_BLOCKED     = "login_blocked"
+	SECURITY_EVENT_ACCOUNT_LOCKED    = "account_locked"
+	SECURITY_EVENT_IP_LOCKED         = "ip_locked"
+	SECURITY_EVENT_TWO_FACTOR_FAILED = "two_factor_failed"
+	SECURITY_EVENT_API_KEY_CREATED   = "api_key_created"
+	SECURITY_EVENT_API_KEY_REVOKED   = "api_key_revoked"
+	SECURITY_EVENT_IDENTITY_LINKED   = "identity_linked"
+)
+
+// the details are given as key value pairs
+func (u *userUsecase) securityEvent(event string, details ...string) {
+	var b strings.Builder
+	b.WriteString("security_event=" + event)
+	for i := 0; i+1 < len(details); i += 2 {
+		b.WriteString(fmt.Sprintf(" %s=%q", details[i], details[i+1]))
+	}
+	u.Middleware.Logger.Warn(b.String())
+}
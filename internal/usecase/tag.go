@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+)
+
+type ITagUsecase interface {
+	GetAllTags(ctx context.Context) ([]*payload.TagInfo, error)
+}
+
+type tagUsecase usecaseType
+
+func (u *tagUsecase) GetAllTags(ctx context.Context) ([]*payload.TagInfo, error) {
+	tags, err := u.Repo.Tag.SelectAllWithArticleCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*payload.TagInfo, 0)
+	for _, tag := range tags {
+		res = append(res, tag.PublicInfo())
+	}
+
+	return res, nil
+}
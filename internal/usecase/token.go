@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+func hashToken(token string) string {
+	return utils.HashToken(token)
+}
+
+// issue a new access token and refresh token for the session, the session id is used as jti
+// and the current role of the user is put in the access token
+func (u *userUsecase) issueTokens(ctx context.Context, session *models.SessionModel, role string) (*payload.TokenResponse, error) {
+	accessToken, err := u.Middleware.JWT.GenerateToken([]byte(session.UserID), session.ID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := u.Middleware.JWT.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiration := u.Middleware.JWT.GetRefreshTokenExpiration()
+
+	err = u.Repo.Session.SaveRefreshToken(ctx, session, hashToken(refreshToken), expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.Repo.Session.Extend(ctx, session, expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// start a new session for the user, used on login and register
+func (u *userUsecase) createSession(ctx context.Context, user *models.UserModel, client *payload.ClientInfo) (*payload.TokenResponse, error) {
+	now := time.Now()
+	session := &models.SessionModel{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if client != nil {
+		session.UserAgent = client.UserAgent
+		session.IP = client.IP
+	}
+
+	err := u.Repo.Session.Create(ctx, session, u.Middleware.JWT.GetRefreshTokenExpiration())
+	if err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(ctx, session, user.Role)
+}
+
+func (u *userUsecase) RefreshToken(ctx context.Context, req *payload.RefreshTokenRequest) (*payload.TokenResponse, error) {
+	tokenHash := hashToken(req.RefreshToken)
+
+	sessionID, err := u.Repo.Session.SelectSessionIDByRefreshToken(ctx, tokenHash)
+	if err == redis.Nil {
+		return nil, payload.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the session is gone when it was revoked by logout or reuse detection
+	session, err := u.Repo.Session.SelectByID(ctx, sessionID)
+	if err == redis.Nil {
+		return nil, payload.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// mark the token as used, if it was already used someone is replaying it
+	// so the whole session is revoked
+	claimed, err := u.Repo.Session.MarkRefreshTokenUsed(ctx, tokenHash, u.Middleware.JWT.GetRefreshTokenExpiration())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// the stolen tokens stay valid when the session could not be revoked, so
+		// that is reported as an internal error instead of a plain reuse
+		err = u.Repo.Session.Delete(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+		return nil, payload.ErrRefreshTokenUsed
+	}
+
+	// the role is read again so role changes apply on the next refresh
+	user, err := u.Repo.User.SelectByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsBanned() {
+		return nil, payload.ErrUserBanned
+	}
+
+	return u.issueTokens(ctx, session, user.Role)
+}
@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/pkg/utils"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	DEFAULT_TOTP_ISSUER = "go-article"
+	TOTP_QR_CODE_SIZE   = 256
+	// a totp code is valid for the current period and the ones next to it,
+	// a used code is remembered that long so it can not be replayed
+	TOTP_REPLAY_WINDOW = 90 * time.Second
+	// marks a totp secret that is encrypted with the server key, secrets saved
+	// before they were encrypted have no prefix
+	TOTP_SECRET_ENCRYPTED_PREFIX = "v1:"
+
+	TWO_FACTOR_CHALLENGE_SIZE       = 32
+	TWO_FACTOR_CHALLENGE_EXPIRATION = 5 * time.Minute
+	// wrong codes allowed on a challenge before the login has to start again,
+	// they are counted as failed logins of the account as well
+	TWO_FACTOR_CHALLENGE_ATTEMPTS = 5
+
+	RECOVERY_CODE_COUNT = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// first step of the login when two factor is on, the password was right so a
+// challenge is handed out that is exchanged for the tokens with a code
+func (u *userUsecase) startTwoFactorChallenge(ctx context.Context, user *models.UserModel) (*payload.UserWithTokenResponse, error) {
+	challenge, err := utils.GenerateRandomToken(TWO_FACTOR_CHALLENGE_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.Repo.OneTimeToken.Create(ctx, repository.TOKEN_PURPOSE_2FA_CHALLENGE, user.ID, hashToken(challenge), TWO_FACTOR_CHALLENGE_EXPIRATION)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.UserWithTokenResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+	}, nil
+}
+
+// the client can be nil like for Login
+func (u *userUsecase) LoginTwoFactor(ctx context.Context, req *payload.TwoFactorLoginRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error) {
+	if client == nil {
+		client = &payload.ClientInfo{}
+	}
+
+	challengeHash := hashToken(req.ChallengeToken)
+
+	userID, err := u.Repo.OneTimeToken.Select(ctx, repository.TOKEN_PURPOSE_2FA_CHALLENGE, challengeHash)
+	if err == redis.Nil {
+		return nil, payload.ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if errors.Is(err, payload.ErrUserNotFound) {
+		return nil, payload.ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return nil, payload.ErrTwoFactorChallengeInvalid
+	}
+
+	if user.IsBanned() {
+		return nil, payload.ErrUserBanned
+	}
+
+	err = u.checkLoginLock(ctx, user.Email, client.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := u.checkTwoFactorCode(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		u.securityEvent(SECURITY_EVENT_TWO_FACTOR_FAILED, "user_id", user.ID, "ip", client.IP)
+		u.countLoginFailures(ctx, user.Email, client.IP)
+
+		attempts, err := u.Repo.Throttle.Hit(ctx, "2FA_CHALLENGE_"+challengeHash, TWO_FACTOR_CHALLENGE_EXPIRATION)
+		if err != nil {
+			return nil, err
+		}
+		if attempts >= TWO_FACTOR_CHALLENGE_ATTEMPTS {
+			u.Repo.OneTimeToken.Consume(ctx, repository.TOKEN_PURPOSE_2FA_CHALLENGE, challengeHash)
+		}
+		return nil, payload.ErrTwoFactorCodeInvalid
+	}
+
+	// consumed only now so a typo does not end the login, a challenge answered
+	// twice at the same time only gives tokens once
+	_, err = u.Repo.OneTimeToken.Consume(ctx, repository.TOKEN_PURPOSE_2FA_CHALLENGE, challengeHash)
+	if err == redis.Nil {
+		return nil, payload.ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	u.resetLoginFailures(ctx, user.Email)
+
+	tokens, err := u.createSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.UserWithTokenResponse{
+		UserInfo:     user.PublicInfo(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+// the code is either a totp code from the authenticator or one of the recovery codes,
+// both can only be used once
+func (u *userUsecase) checkTwoFactorCode(ctx context.Context, user *models.UserModel, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if isTotpCode(code) {
+		if user.TotpSecret == nil {
+			return false, nil
+		}
+		secret, err := u.decryptTotpSecret(*user.TotpSecret)
+		if err != nil {
+			return false, err
+		}
+		if !totp.Validate(code, secret) {
+			return false, nil
+		}
+		return u.Repo.Throttle.Allow(ctx, "TOTP_USED_"+user.ID+"_"+code, TOTP_REPLAY_WINDOW)
+	}
+
+	return u.Repo.RecoveryCode.Use(ctx, user.ID, hashRecoveryCode(code))
+}
+
+// the secrets are stored encrypted so a leaked database does not give away the second factor
+func (u *userUsecase) encryptTotpSecret(secret string) (string, error) {
+	encrypted, err := utils.Encrypt(secret, u.ServerInfo.TotpEncryptionKey)
+	if err != nil {
+		return "", err
+	}
+	return TOTP_SECRET_ENCRYPTED_PREFIX + encrypted, nil
+}
+
+func (u *userUsecase) decryptTotpSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, TOTP_SECRET_ENCRYPTED_PREFIX) {
+		return stored, nil
+	}
+	return utils.Decrypt(strings.TrimPrefix(stored, TOTP_SECRET_ENCRYPTED_PREFIX), u.ServerInfo.TotpEncryptionKey)
+}
+
+func isTotpCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// start the enrollment with a new secret, two factor is only on after the
+// enrollment is confirmed with a code so a failed scan does not lock the user out
+func (u *userUsecase) EnrollTwoFactor(ctx context.Context, userID string) (*payload.TwoFactorEnrollResponse, error) {
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, payload.ErrTwoFactorEnabled
+	}
+
+	issuer := u.ServerInfo.Name
+	if issuer == "" {
+		issuer = DEFAULT_TOTP_ISSUER
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: issuer, AccountName: user.Email})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := key.Image(TOTP_QR_CODE_SIZE, TOTP_QR_CODE_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	var qrCode bytes.Buffer
+	err = png.Encode(&qrCode, image)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := key.Secret()
+	encrypted, err := u.encryptTotpSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	user.TotpSecret = &encrypted
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.User.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.TwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// turn two factor on, the recovery codes are only shown in this response
+func (u *userUsecase) ConfirmTwoFactor(ctx context.Context, userID string, req *payload.TwoFactorCodeRequest) (*payload.RecoveryCodesResponse, error) {
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, payload.ErrTwoFactorEnabled
+	}
+
+	if user.TotpSecret == nil {
+		return nil, payload.ErrTwoFactorNotEnrolled
+	}
+
+	// there are no recovery codes yet, the authenticator has to be used
+	if !isTotpCode(strings.TrimSpace(req.Code)) {
+		return nil, payload.ErrTwoFactorCodeInvalid
+	}
+
+	valid, err := u.checkTwoFactorCode(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, payload.ErrTwoFactorCodeInvalid
+	}
+
+	codes, recoveryCodes, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TotpEnabledAt = &now
+
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		err := u.Repo.User.Update(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		err = u.Repo.RecoveryCode.DeleteByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return u.Repo.RecoveryCode.Create(ctx, recoveryCodes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// turning two factor off needs the password and a code, a stolen token alone is not enough
+func (u *userUsecase) DisableTwoFactor(ctx context.Context, userID string, req *payload.TwoFactorDisableRequest) error {
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return payload.ErrTwoFactorNotEnabled
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	if err != nil {
+		return payload.ErrWrongPassword
+	}
+
+	valid, err := u.checkTwoFactorCode(ctx, user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return payload.ErrTwoFactorCodeInvalid
+	}
+
+	user.TotpSecret = nil
+	user.TotpEnabledAt = nil
+
+	return u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		err := u.Repo.User.Update(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return u.Repo.RecoveryCode.DeleteByUserID(ctx, user.ID)
+	})
+}
+
+// recovery codes look like abcde-fghij, only their hash is stored
+func generateRecoveryCodes(userID string) ([]string, []*models.RecoveryCodeModel, error) {
+	codes := make([]string, 0, RECOVERY_CODE_COUNT)
+	res := make([]*models.RecoveryCodeModel, 0, RECOVERY_CODE_COUNT)
+
+	for i := 0; i < RECOVERY_CODE_COUNT; i++ {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		code = code[:5] + "-" + code[5:]
+
+		codes = append(codes, code)
+		res = append(res, &models.RecoveryCodeModel{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+	return codes, res, nil
+}
+
+// the dash and the case do not matter when the code is typed in
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return hashToken(code)
+}
@@ -1,30 +1,57 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/haikalvidya/go-article/config"
 	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/models"
 	"github.com/haikalvidya/go-article/internal/repository"
-
-	"github.com/go-redis/redis"
+	"github.com/haikalvidya/go-article/pkg/cache"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+	"github.com/haikalvidya/go-article/pkg/oidc"
+	"github.com/haikalvidya/go-article/pkg/search"
 )
 
 type Usecase struct {
-	User    IUserUsecase
-	Article IArticleUsecase
+	User            IUserUsecase
+	Article         IArticleUsecase
+	ArticleRevision IArticleRevisionUsecase
+	Tag             ITagUsecase
+	Comment         ICommentUsecase
+	Internal        IInternalUsecase
 }
 
 type usecaseType struct {
-	Repo        *repository.Repository
-	Middleware  *middlewares.CustomMiddleware
-	RedisClient *redis.Client
-	ServerInfo  *config.ServerConfig
+	Repo       *repository.Repository
+	Middleware *middlewares.CustomMiddleware
+	Cache      cache.Cache
+	ServerInfo *config.ServerConfig
+	Search     search.SearchIndex
+	Mailer     mailer.Mailer
+	// nil when the login with openid connect is off
+	OIDC *oidc.Provider
 }
 
-func NewUsecase(repo *repository.Repository, mid *middlewares.CustomMiddleware, redis *redis.Client, serverInfo *config.ServerConfig) *Usecase {
-	usc := &usecaseType{Repo: repo, Middleware: mid, RedisClient: redis, ServerInfo: serverInfo}
+func NewUsecase(repo *repository.Repository, mid *middlewares.CustomMiddleware, cache cache.Cache, serverInfo *config.ServerConfig, searchIndex search.SearchIndex, mail mailer.Mailer, oidcProvider *oidc.Provider) *Usecase {
+	usc := &usecaseType{Repo: repo, Middleware: mid, Cache: cache, ServerInfo: serverInfo, Search: searchIndex, Mailer: mail, OIDC: oidcProvider}
 
 	return &Usecase{
-		User:    (*userUsecase)(usc),
-		Article: (*articleUsecase)(usc),
+		User:            (*userUsecase)(usc),
+		Article:         (*articleUsecase)(usc),
+		ArticleRevision: (*articleRevisionUsecase)(usc),
+		Tag:             (*tagUsecase)(usc),
+		Comment:         (*commentUsecase)(usc),
+		Internal:        (*internalUsecase)(usc),
+	}
+}
+
+// load the user doing the action, the policy checks the role stored in the
+// database so a role change applies right away
+func getActor(ctx context.Context, repo *repository.Repository, userID string) (*models.UserModel, error) {
+	user, err := repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
+	return user, nil
 }
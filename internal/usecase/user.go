@@ -1,33 +1,53 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 
 	"github.com/haikalvidya/go-article/internal/delivery/payload"
 	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/policy"
+	"github.com/haikalvidya/go-article/pkg/common"
+	"github.com/haikalvidya/go-article/pkg/utils"
 
+	"github.com/go-redis/redis/v8"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 type IUserUsecase interface {
-	Register(req *payload.RegisterUserRequest) (*payload.UserWithTokenResponse, error)
-	Login(req *payload.LoginUserRequest) (*payload.UserWithTokenResponse, error)
-	DeleteAccount(userID string) error
-	UpdateUser(userID string, req *payload.UpdateUserRequest) error
-	Logout(userID string) error
-	GetUser(userID string) (*payload.UserInfo, error)
-	GetUserByName(name string) (*payload.UserInfo, error)
+	Register(ctx context.Context, req *payload.RegisterUserRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error)
+	Login(ctx context.Context, req *payload.LoginUserRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error)
+	DeleteAccount(ctx context.Context, userID string) error
+	UpdateUser(ctx context.Context, userID string, req *payload.UpdateUserRequest) error
+	Logout(ctx context.Context, userID string, sessionID string) error
+	LogoutAll(ctx context.Context, userID string) error
+	GetSessions(ctx context.Context, userID string, currentSessionID string) ([]*payload.SessionInfo, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	GetUser(ctx context.Context, userID string) (*payload.UserInfo, error)
+	GetUserByName(ctx context.Context, name string) (*payload.UserInfo, error)
+	RefreshToken(ctx context.Context, req *payload.RefreshTokenRequest) (*payload.TokenResponse, error)
+	ForgotPassword(ctx context.Context, req *payload.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *payload.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, req *payload.VerifyEmailRequest) (*payload.UserInfo, error)
+	ResendVerificationEmail(ctx context.Context, userID string) error
+	LoginTwoFactor(ctx context.Context, req *payload.TwoFactorLoginRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error)
+	EnrollTwoFactor(ctx context.Context, userID string) (*payload.TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userID string, req *payload.TwoFactorCodeRequest) (*payload.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userID string, req *payload.TwoFactorDisableRequest) error
+	GetUsers(ctx context.Context, pagination *common.PaginationRequest) ([]*payload.UserInfo, *common.Pagination, error)
+	UpdateUserRole(ctx context.Context, actorID string, userID string, req *payload.UpdateUserRoleRequest) (*payload.UserInfo, error)
+	DeleteUser(ctx context.Context, actorID string, userID string) error
+	CreateAPIKey(ctx context.Context, userID string, req *payload.CreateAPIKeyRequest) (*payload.CreateAPIKeyResponse, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]*payload.APIKeyInfo, error)
+	RevokeAPIKey(ctx context.Context, userID string, keyID string) error
+	StartOIDCLogin(ctx context.Context) (*payload.OIDCLoginResponse, error)
+	LoginOIDC(ctx context.Context, req *payload.OIDCCallbackRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error)
 }
 
 type userUsecase usecaseType
 
-func (u *userUsecase) GetUser(userID string) (*payload.UserInfo, error) {
-	_, err := u.RedisClient.Get(userID).Result()
-	if err != nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
-	}
-	user, err := u.Repo.User.SelectByID(userID)
+func (u *userUsecase) GetUser(ctx context.Context, userID string) (*payload.UserInfo, error) {
+	user, err := u.Repo.User.SelectByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,14 +55,14 @@ func (u *userUsecase) GetUser(userID string) (*payload.UserInfo, error) {
 	return user.PublicInfo(), nil
 }
 
-func (u *userUsecase) Register(req *payload.RegisterUserRequest) (*payload.UserWithTokenResponse, error) {
-	_, err := u.Repo.User.SelectByEmail(req.Email)
+func (u *userUsecase) Register(ctx context.Context, req *payload.RegisterUserRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error) {
+	_, err := u.Repo.User.SelectByEmail(ctx, req.Email)
 	if err == nil {
-		return nil, errors.New(payload.ERROR_USER_EXIST)
+		return nil, payload.ErrUserExist
 	}
 
 	if req.Password != req.PasswordConfirmation {
-		return nil, errors.New(payload.ERROR_PASSWORD_NOT_MATCH)
+		return nil, payload.ErrPasswordNotMatch
 	}
 
 	password, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -52,8 +72,8 @@ func (u *userUsecase) Register(req *payload.RegisterUserRequest) (*payload.UserW
 		Name:     req.Name,
 	}
 
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
-		createdUser, err := u.Repo.User.CreateTx(tx, userModel)
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		createdUser, err := u.Repo.User.Create(ctx, userModel)
 		if err != nil {
 			return err
 		}
@@ -65,88 +85,145 @@ func (u *userUsecase) Register(req *payload.RegisterUserRequest) (*payload.UserW
 		return nil, err
 	}
 
-	var accessToken string
-	accessToken, _ = u.Middleware.JWT.GenerateToken([]byte(userModel.ID))
+	tokens, err := u.createSession(ctx, userModel, client)
+	if err != nil {
+		return nil, err
+	}
+
+	u.sendVerificationEmail(ctx, userModel)
 
-	u.RedisClient.Set(userModel.ID, accessToken, 0)
 	return &payload.UserWithTokenResponse{
-		UserInfo: userModel.PublicInfo(),
-		Token:    accessToken,
+		UserInfo:     userModel.PublicInfo(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 
 }
 
-func (u *userUsecase) Login(req *payload.LoginUserRequest) (*payload.UserWithTokenResponse, error) {
-	user, err := u.Repo.User.SelectByEmail(req.Email)
+// the client is nil when the login does not come from a request, like for
+// createSession. only the account is throttled then
+func (u *userUsecase) Login(ctx context.Context, req *payload.LoginUserRequest, client *payload.ClientInfo) (*payload.UserWithTokenResponse, error) {
+	if client == nil {
+		client = &payload.ClientInfo{}
+	}
+
+	err := u.checkLoginLock(ctx, req.Email, client.IP)
 	if err != nil {
-		return nil, errors.New(payload.ERROR_USER_NOT_FOUND)
+		return nil, err
+	}
+
+	// an unknown email and a wrong password look the same, even in how long they take
+	user, err := u.Repo.User.SelectByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, payload.ErrUserNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(req.Password))
+		u.recordLoginFailure(ctx, req.Email, client.IP)
+		return nil, payload.ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New(payload.ERROR_WRONG_PASSWORD)
+		u.recordLoginFailure(ctx, req.Email, client.IP)
+		return nil, payload.ErrInvalidCredentials
+	}
+
+	if user.IsBanned() {
+		return nil, payload.ErrUserBanned
+	}
+
+	// the failures are reset once the second factor is right too
+	if user.IsTwoFactorEnabled() {
+		return u.startTwoFactorChallenge(ctx, user)
+	}
+
+	u.resetLoginFailures(ctx, req.Email)
+
+	tokens, err := u.createSession(ctx, user, client)
+	if err != nil {
+		return nil, err
 	}
 
-	var accessToken string
-	accessToken, _ = u.Middleware.JWT.GenerateToken([]byte(user.ID))
-	u.RedisClient.Set(user.ID, accessToken, 0)
 	return &payload.UserWithTokenResponse{
-		UserInfo: user.PublicInfo(),
-		Token:    accessToken,
+		UserInfo:     user.PublicInfo(),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
-func (u *userUsecase) DeleteAccount(userID string) error {
-	// check in redis if user is logged in
-	_, err := u.RedisClient.Get(userID).Result()
-	if err != nil {
-		return errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
-	}
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
+func (u *userUsecase) DeleteAccount(ctx context.Context, userID string) error {
+	err := u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
 		user := &models.UserModel{
 			ID: userID,
 		}
-		err := u.Repo.User.DeleteTx(tx, user)
+		err := u.Repo.User.Delete(ctx, user)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return u.Repo.Session.DeleteByUserID(ctx, userID)
+}
+
+func (u *userUsecase) Logout(ctx context.Context, userID string, sessionID string) error {
+	return u.RevokeSession(ctx, userID, sessionID)
+}
 
-	return err
+func (u *userUsecase) LogoutAll(ctx context.Context, userID string) error {
+	return u.Repo.Session.DeleteByUserID(ctx, userID)
 }
 
-func (u *userUsecase) Logout(userID string) error {
-	_, err := u.RedisClient.Get(userID).Result()
+func (u *userUsecase) GetSessions(ctx context.Context, userID string, currentSessionID string) ([]*payload.SessionInfo, error) {
+	sessions, err := u.Repo.Session.SelectByUserID(ctx, userID)
 	if err != nil {
-		return errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
+		return nil, err
 	}
-	err = u.RedisClient.Del(userID).Err()
-	if err != nil {
-		return err
+
+	res := make([]*payload.SessionInfo, 0)
+	for _, session := range sessions {
+		res = append(res, session.PublicInfo(currentSessionID))
 	}
-	return nil
+
+	return res, nil
 }
 
-func (u *userUsecase) UpdateUser(userID string, req *payload.UpdateUserRequest) error {
-	_, err := u.RedisClient.Get(userID).Result()
+func (u *userUsecase) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := u.Repo.Session.SelectByID(ctx, sessionID)
+	if err == redis.Nil {
+		return payload.ErrSessionNotFound
+	}
 	if err != nil {
-		return errors.New(payload.ERROR_USER_NOT_LOGGED_IN)
+		return err
+	}
+	if session.UserID != userID {
+		return payload.ErrSessionNotFound
 	}
 
+	return u.Repo.Session.Delete(ctx, session)
+}
+
+func (u *userUsecase) UpdateUser(ctx context.Context, userID string, req *payload.UpdateUserRequest) error {
 	if req.Password != nil && *req.Password != "" {
 		if req.PasswordConfirmation != nil && *req.PasswordConfirmation != "" {
 			if *req.Password != *req.PasswordConfirmation {
-				return errors.New(payload.ERROR_PASSWORD_NOT_MATCH)
+				return payload.ErrPasswordNotMatch
 			}
 		} else {
-			return errors.New(payload.ERROR_PASSWORD_NOT_MATCH)
+			return payload.ErrPasswordNotMatch
 		}
 	}
 
-	err = u.Repo.Tx.DoInTransaction(func(tx *gorm.DB) error {
+	// set when the email changed, the new email has to be verified again
+	var emailChanged *models.UserModel
+
+	err := u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
 		// get user from db
-		user, err := u.Repo.User.SelectByID(userID)
+		user, err := u.Repo.User.SelectByID(ctx, userID)
 		if err != nil {
 			return err
 		}
@@ -156,32 +233,108 @@ func (u *userUsecase) UpdateUser(userID string, req *payload.UpdateUserRequest)
 		}
 
 		if req.Email != nil && *req.Email != "" && *req.Email != user.Email {
-			_, err = u.Repo.User.SelectByEmail(*req.Email)
+			_, err = u.Repo.User.SelectByEmail(ctx, *req.Email)
 			if err == nil {
-				return errors.New(payload.ERROR_USER_EXIST)
+				return payload.ErrUserExist
 			}
 			user.Email = *req.Email
+			user.EmailVerifiedAt = nil
+			emailChanged = user
 		}
 
 		if req.Name != nil && *req.Name != "" {
 			user.Name = *req.Name
 		}
 
-		err = u.Repo.User.UpdateTx(tx, user)
+		err = u.Repo.User.Update(ctx, user)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if emailChanged != nil {
+		u.sendVerificationEmail(ctx, emailChanged)
+	}
+	return nil
+}
+
+func (u *userUsecase) GetUserByName(ctx context.Context, name string) (*payload.UserInfo, error) {
+	user, err := u.Repo.User.SelectByName(ctx, name)
+	if err != nil {
+		return nil, payload.ErrAuthorNotFound
+	}
 
-	return err
+	return user.PublicInfo(), nil
 }
 
-func (u *userUsecase) GetUserByName(name string) (*payload.UserInfo, error) {
-	user, err := u.Repo.User.SelectByName(name)
+func (u *userUsecase) GetUsers(ctx context.Context, pagination *common.PaginationRequest) ([]*payload.UserInfo, *common.Pagination, error) {
+	users, total, err := u.Repo.User.SelectAll(ctx, pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := make([]*payload.UserInfo, 0)
+	for _, user := range users {
+		res = append(res, user.PublicInfo())
+	}
+
+	return res, utils.CalculateMetaPagination(total, pagination), nil
+}
+
+// the sessions of the user are revoked so the new role is in the next token they get
+func (u *userUsecase) UpdateUserRole(ctx context.Context, actorID string, userID string, req *payload.UpdateUserRoleRequest) (*payload.UserInfo, error) {
+	actor, err := getActor(ctx, u.Repo, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.CanAssignRole(actor, user, req.Role) {
+		return nil, payload.ErrRoleNotAllowed
+	}
+
+	if user.Role == req.Role {
+		return user.PublicInfo(), nil
+	}
+
+	user.Role = req.Role
+	err = u.Repo.Tx.DoInTransaction(ctx, func(ctx context.Context) error {
+		return u.Repo.User.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.Repo.Session.DeleteByUserID(ctx, user.ID)
 	if err != nil {
-		return nil, errors.New(payload.ERROR_AUTHOR_NOT_FOUND)
+		return nil, err
 	}
 
 	return user.PublicInfo(), nil
 }
+
+func (u *userUsecase) DeleteUser(ctx context.Context, actorID string, userID string) error {
+	actor, err := getActor(ctx, u.Repo, actorID)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.Repo.User.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !policy.CanManageUser(actor, user) {
+		return payload.ErrUserNotAllowed
+	}
+
+	return u.DeleteAccount(ctx, user.ID)
+}
@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haikalvidya/go-article/internal/app/apptest"
+	"github.com/haikalvidya/go-article/internal/delivery/payload"
+	"github.com/haikalvidya/go-article/internal/middlewares"
+	"github.com/haikalvidya/go-article/internal/models"
+	"github.com/haikalvidya/go-article/internal/repository"
+	"github.com/haikalvidya/go-article/internal/repository/memory"
+	"github.com/haikalvidya/go-article/internal/usecase"
+	"github.com/haikalvidya/go-article/pkg/cache"
+	"github.com/haikalvidya/go-article/pkg/mailer"
+	"github.com/haikalvidya/go-article/pkg/search"
+)
+
+func newUsecase(t *testing.T, repo *repository.Repository) *usecase.Usecase {
+	t.Helper()
+
+	cfg := apptest.DefaultConfig()
+	searchIndex, err := search.NewLocalIndex("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usecase.NewUsecase(repo, middlewares.New(cfg, repo, nil), cache.NewMemoryCache(), &cfg.Server, searchIndex, mailer.NewMemoryMailer(), nil)
+}
+
+// callers without a request give no client, like createSession allows
+func TestLoginWithoutClient(t *testing.T) {
+	ctx := context.Background()
+	usc := newUsecase(t, memory.NewRepository())
+
+	_, err := usc.User.Register(ctx, &payload.RegisterUserRequest{
+		Name:                 "User",
+		Email:                "user@example.com",
+		Password:             "password",
+		PasswordConfirmation: "password",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login, err := usc.User.Login(ctx, &payload.LoginUserRequest{Email: "user@example.com", Password: "password"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.Token == "" {
+		t.Errorf("login = %+v, want tokens", login)
+	}
+
+	// the account is still throttled
+	for i := 0; i < 6; i++ {
+		_, err = usc.User.Login(ctx, &payload.LoginUserRequest{Email: "user@example.com", Password: "wrong-password"}, nil)
+		if !errors.Is(err, payload.ErrInvalidCredentials) {
+			t.Fatalf("failure %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	_, err = usc.User.Login(ctx, &payload.LoginUserRequest{Email: "user@example.com", Password: "password"}, nil)
+	if !errors.Is(err, payload.ErrLoginLocked) {
+		t.Errorf("err = %v, want ErrLoginLocked", err)
+	}
+
+	_, err = usc.User.LoginTwoFactor(ctx, &payload.TwoFactorLoginRequest{ChallengeToken: "unknown", Code: "000000"}, nil)
+	if !errors.Is(err, payload.ErrTwoFactorChallengeInvalid) {
+		t.Errorf("err = %v, want ErrTwoFactorChallengeInvalid", err)
+	}
+}
+
+// a user repository whose database is down
+type failingUserRepository struct {
+	repository.IUserRepository
+	err error
+}
+
+func (r *failingUserRepository) SelectByID(ctx context.Context, id string) (*models.UserModel, error) {
+	return nil, r.err
+}
+
+func (r *failingUserRepository) SelectByEmail(ctx context.Context, email string) (*models.UserModel, error) {
+	return nil, r.err
+}
+
+// only a missing row is reported as not found, a failing database is an
+// internal error and not a 404 or 401
+func TestRepositoryErrorsAreNotNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	err := newUsecase(t, memory.NewRepository()).Article.DeleteArticleByID(ctx, 1, "unknown")
+	if !errors.Is(err, payload.ErrUserNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrUserNotFound", err)
+	}
+
+	errDatabase := errors.New("database is down")
+	repo := memory.NewRepository()
+	repo.User = &failingUserRepository{IUserRepository: repo.User, err: errDatabase}
+	usc := newUsecase(t, repo)
+
+	err = usc.Article.DeleteArticleByID(ctx, 1, "user")
+	if !errors.Is(err, errDatabase) || errors.Is(err, payload.ErrUserNotFound) {
+		t.Errorf("actor: err = %v, want the database error", err)
+	}
+
+	_, err = usc.User.Login(ctx, &payload.LoginUserRequest{Email: "user@example.com", Password: "password"}, nil)
+	if !errors.Is(err, errDatabase) {
+		t.Errorf("login: err = %v, want the database error", err)
+	}
+
+	err = usc.User.ForgotPassword(ctx, &payload.ForgotPasswordRequest{Email: "user@example.com"})
+	if !errors.Is(err, errDatabase) {
+		t.Errorf("forgot password: err = %v, want the database error", err)
+	}
+}
@@ -0,0 +1,14 @@
+-- migrate:up
+ALTER TABLE `articles`
+    ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'draft' AFTER `body`,
+    ADD COLUMN `published_at` TIMESTAMP NULL AFTER `status`,
+    ADD INDEX `idx_articles_status` (`status`);
+
+-- articles created before the lifecycle existed were already public
+UPDATE `articles` SET `status` = 'published', `published_at` = `created_at`;
+
+-- migrate:down
+ALTER TABLE `articles`
+    DROP INDEX `idx_articles_status`,
+    DROP COLUMN `published_at`,
+    DROP COLUMN `status`;
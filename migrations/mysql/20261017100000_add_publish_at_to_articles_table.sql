@@ -0,0 +1,9 @@
+-- migrate:up
+ALTER TABLE `articles`
+    ADD COLUMN `publish_at` TIMESTAMP NULL AFTER `published_at`,
+    ADD INDEX `idx_articles_status_publish_at` (`status`, `publish_at`);
+
+-- migrate:down
+ALTER TABLE `articles`
+    DROP INDEX `idx_articles_status_publish_at`,
+    DROP COLUMN `publish_at`;
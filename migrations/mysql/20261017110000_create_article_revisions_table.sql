@@ -0,0 +1,22 @@
+-- migrate:up
+CREATE TABLE `article_revisions` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `article_id` INT NOT NULL,
+    `revision` INT NOT NULL,
+    `title` TEXT NOT NULL,
+    `body` TEXT NOT NULL,
+    `editor_id` CHAR(36) NOT NULL,
+    `restored_from` INT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uq_article_revisions_article_id_revision` (`article_id`, `revision`),
+    FOREIGN KEY (`article_id`) REFERENCES `articles`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (`editor_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- existing articles start their history from the current content
+INSERT INTO `article_revisions` (`article_id`, `revision`, `title`, `body`, `editor_id`, `created_at`)
+SELECT `id`, 1, `title`, `body`, `author_id`, COALESCE(`updated_at`, `created_at`) FROM `articles`;
+
+-- migrate:down
+DROP TABLE `article_revisions`
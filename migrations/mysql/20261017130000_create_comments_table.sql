@@ -0,0 +1,20 @@
+-- migrate:up
+CREATE TABLE `comments` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `article_id` INT NOT NULL,
+    `author_id` CHAR(36) NOT NULL,
+    `parent_id` INT NULL,
+    `body` TEXT NOT NULL,
+    `hidden_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP on update CURRENT_TIMESTAMP NULL,
+    `deleted_at` TIMESTAMP NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_comments_article_id` (`article_id`),
+    FOREIGN KEY (`article_id`) REFERENCES `articles`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (`author_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (`parent_id`) REFERENCES `comments`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- migrate:down
+DROP TABLE `comments`
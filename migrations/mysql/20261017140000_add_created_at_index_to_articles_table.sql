@@ -0,0 +1,7 @@
+-- migrate:up
+ALTER TABLE `articles`
+    ADD INDEX `idx_articles_status_created_at_id` (`status`, `created_at`, `id`);
+
+-- migrate:down
+ALTER TABLE `articles`
+    DROP INDEX `idx_articles_status_created_at_id`;
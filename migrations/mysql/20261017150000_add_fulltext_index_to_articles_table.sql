@@ -0,0 +1,7 @@
+-- migrate:up
+ALTER TABLE `articles`
+    ADD FULLTEXT INDEX `idx_articles_title_body` (`title`, `body`);
+
+-- migrate:down
+ALTER TABLE `articles`
+    DROP INDEX `idx_articles_title_body`;
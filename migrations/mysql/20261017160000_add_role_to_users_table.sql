@@ -0,0 +1,7 @@
+-- migrate:up
+ALTER TABLE `users`
+    ADD COLUMN `role` VARCHAR(20) NOT NULL DEFAULT 'author' AFTER `password`;
+
+-- migrate:down
+ALTER TABLE `users`
+    DROP COLUMN `role`;
@@ -0,0 +1,7 @@
+-- migrate:up
+ALTER TABLE `users`
+    ADD COLUMN `banned_at` TIMESTAMP NULL AFTER `role`;
+
+-- migrate:down
+ALTER TABLE `users`
+    DROP COLUMN `banned_at`;
@@ -0,0 +1,22 @@
+-- migrate:up
+ALTER TABLE `users`
+    ADD COLUMN `totp_secret` VARCHAR(64) NULL AFTER `password`,
+    ADD COLUMN `totp_enabled_at` TIMESTAMP NULL AFTER `totp_secret`;
+
+CREATE TABLE `user_recovery_codes` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `user_id` CHAR(36) NOT NULL,
+    `code_hash` CHAR(64) NOT NULL,
+    `used_at` TIMESTAMP NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_recovery_codes_user_id_code_hash` (`user_id`, `code_hash`),
+    FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- migrate:down
+DROP TABLE `user_recovery_codes`;
+
+ALTER TABLE `users`
+    DROP COLUMN `totp_enabled_at`,
+    DROP COLUMN `totp_secret`;
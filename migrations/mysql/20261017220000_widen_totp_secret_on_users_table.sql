@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE `users` MODIFY COLUMN `totp_secret` VARCHAR(255) NULL;
+
+-- migrate:down
+ALTER TABLE `users` MODIFY COLUMN `totp_secret` VARCHAR(64) NULL;
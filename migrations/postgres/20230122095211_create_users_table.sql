@@ -0,0 +1,17 @@
+-- migrate:up
+CREATE TABLE users (
+    id CHAR(36) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    password VARCHAR(190) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    PRIMARY KEY (id)
+);
+
+-- emails are looked up case insensitive
+CREATE INDEX idx_users_lower_email ON users (LOWER(email));
+
+-- migrate:down
+DROP TABLE users;
@@ -0,0 +1,15 @@
+-- migrate:up
+CREATE TABLE articles (
+    id SERIAL NOT NULL,
+    author_id CHAR(36) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- migrate:down
+DROP TABLE articles;
@@ -0,0 +1,11 @@
+-- migrate:up
+ALTER TABLE articles
+    ADD COLUMN publish_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_articles_status_publish_at ON articles (status, publish_at);
+
+-- migrate:down
+DROP INDEX idx_articles_status_publish_at;
+
+ALTER TABLE articles
+    DROP COLUMN publish_at;
@@ -0,0 +1,22 @@
+-- migrate:up
+CREATE TABLE tags (
+    id SERIAL NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_tags_name UNIQUE (name)
+);
+
+CREATE TABLE article_tags (
+    article_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (article_id, tag_id),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_article_tags_tag_id ON article_tags (tag_id);
+
+-- migrate:down
+DROP TABLE article_tags;
+DROP TABLE tags;
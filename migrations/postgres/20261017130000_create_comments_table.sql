@@ -0,0 +1,21 @@
+-- migrate:up
+CREATE TABLE comments (
+    id SERIAL NOT NULL,
+    article_id INT NOT NULL,
+    author_id CHAR(36) NOT NULL,
+    parent_id INT NULL,
+    body TEXT NOT NULL,
+    hidden_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_comments_article_id ON comments (article_id);
+
+-- migrate:down
+DROP TABLE comments;
@@ -0,0 +1,6 @@
+-- migrate:up
+-- the search has to use the same expression for the index to be used
+CREATE INDEX idx_articles_title_body ON articles USING GIN (to_tsvector('simple', title || ' ' || body));
+
+-- migrate:down
+DROP INDEX idx_articles_title_body;
@@ -0,0 +1,22 @@
+-- migrate:up
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(64) NULL,
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ NULL;
+
+CREATE TABLE user_recovery_codes (
+    id SERIAL NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_user_recovery_codes_user_id_code_hash UNIQUE (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- migrate:down
+DROP TABLE user_recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_enabled_at,
+    DROP COLUMN totp_secret;
@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE users ALTER COLUMN totp_secret TYPE VARCHAR(255);
+
+-- migrate:down
+ALTER TABLE users ALTER COLUMN totp_secret TYPE VARCHAR(64);
@@ -0,0 +1,14 @@
+-- migrate:up
+CREATE TABLE users (
+    id CHAR(36) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    password VARCHAR(190) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    PRIMARY KEY (id)
+);
+
+-- migrate:down
+DROP TABLE users;
@@ -0,0 +1,14 @@
+-- migrate:up
+ALTER TABLE articles ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft';
+ALTER TABLE articles ADD COLUMN published_at DATETIME NULL;
+
+CREATE INDEX idx_articles_status ON articles (status);
+
+-- articles created before the lifecycle existed were already public
+UPDATE articles SET status = 'published', published_at = created_at;
+
+-- migrate:down
+DROP INDEX idx_articles_status;
+
+ALTER TABLE articles DROP COLUMN published_at;
+ALTER TABLE articles DROP COLUMN status;
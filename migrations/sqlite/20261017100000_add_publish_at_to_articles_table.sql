@@ -0,0 +1,9 @@
+-- migrate:up
+ALTER TABLE articles ADD COLUMN publish_at DATETIME NULL;
+
+CREATE INDEX idx_articles_status_publish_at ON articles (status, publish_at);
+
+-- migrate:down
+DROP INDEX idx_articles_status_publish_at;
+
+ALTER TABLE articles DROP COLUMN publish_at;
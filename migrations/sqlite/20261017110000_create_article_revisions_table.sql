@@ -0,0 +1,21 @@
+-- migrate:up
+CREATE TABLE article_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    editor_id CHAR(36) NOT NULL,
+    restored_from INTEGER NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_article_revisions_article_id_revision UNIQUE (article_id, revision),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- existing articles start their history from the current content
+INSERT INTO article_revisions (article_id, revision, title, body, editor_id, created_at)
+SELECT id, 1, title, body, author_id, COALESCE(updated_at, created_at) FROM articles;
+
+-- migrate:down
+DROP TABLE article_revisions;
@@ -0,0 +1,20 @@
+-- migrate:up
+CREATE TABLE comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL,
+    author_id CHAR(36) NOT NULL,
+    parent_id INTEGER NULL,
+    body TEXT NOT NULL,
+    hidden_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_comments_article_id ON comments (article_id);
+
+-- migrate:down
+DROP TABLE comments;
@@ -0,0 +1,5 @@
+-- migrate:up
+CREATE INDEX idx_articles_status_created_at_id ON articles (status, created_at, id);
+
+-- migrate:down
+DROP INDEX idx_articles_status_created_at_id;
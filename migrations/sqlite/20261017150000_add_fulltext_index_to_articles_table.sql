@@ -0,0 +1,4 @@
+-- migrate:up
+-- sqlite has no fulltext index, the database search backend matches the articles with LIKE
+
+-- migrate:down
@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN banned_at DATETIME NULL;
+
+-- migrate:down
+ALTER TABLE users DROP COLUMN banned_at;
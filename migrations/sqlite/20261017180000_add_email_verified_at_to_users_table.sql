@@ -0,0 +1,8 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+
+-- the users that registered before verification existed are trusted
+UPDATE users SET email_verified_at = created_at;
+
+-- migrate:down
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,4 @@
+-- migrate:up
+-- sqlite does not enforce the length of a varchar, the encrypted secrets fit as they are
+
+-- migrate:down
@@ -0,0 +1,129 @@
+// Package apperror holds the errors the usecases return to the clients. Each
+// error has a kind that picks the http status and a stable code the clients
+// can check instead of the message.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Kind string
+
+const (
+	KIND_NOT_FOUND         Kind = "not_found"
+	KIND_FORBIDDEN         Kind = "forbidden"
+	KIND_UNAUTHORIZED      Kind = "unauthorized"
+	KIND_CONFLICT          Kind = "conflict"
+	KIND_VALIDATION        Kind = "validation"
+	KIND_TOO_MANY_REQUESTS Kind = "too_many_requests"
+	KIND_INTERNAL          Kind = "internal"
+)
+
+const (
+	CODE_INTERNAL   = "internal_error"
+	CODE_VALIDATION = "validation_failed"
+)
+
+// the request body or query is not valid, the details hold the message of each field
+var ErrValidation = Validation(CODE_VALIDATION, "request is not valid")
+
+var kindStatus = map[Kind]int{
+	KIND_NOT_FOUND:         http.StatusNotFound,
+	KIND_FORBIDDEN:         http.StatusForbidden,
+	KIND_UNAUTHORIZED:      http.StatusUnauthorized,
+	KIND_CONFLICT:          http.StatusConflict,
+	KIND_VALIDATION:        http.StatusBadRequest,
+	KIND_TOO_MANY_REQUESTS: http.StatusTooManyRequests,
+	KIND_INTERNAL:          http.StatusInternalServerError,
+}
+
+type Error struct {
+	Kind Kind
+	// stable machine readable code, like article_not_found
+	Code    string
+	Message string
+	// extra data for the client, like the invalid fields of a request
+	Details interface{}
+	// the cause, it is logged but never shown to the client
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// errors with the same code match, so errors.Is finds a sentinel error even
+// when it was wrapped or given details
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// a copy of the error with the cause set
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// a copy of the error with the details set
+func (e *Error) WithDetails(details interface{}) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+func (e *Error) HTTPStatus() int {
+	status, ok := kindStatus[e.Kind]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KIND_NOT_FOUND, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KIND_FORBIDDEN, code, message)
+}
+
+func Unauthorized(code string, message string) *Error {
+	return New(KIND_UNAUTHORIZED, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KIND_CONFLICT, code, message)
+}
+
+func Validation(code string, message string) *Error {
+	return New(KIND_VALIDATION, code, message)
+}
+
+func TooManyRequests(code string, message string) *Error {
+	return New(KIND_TOO_MANY_REQUESTS, code, message)
+}
+
+// an unexpected error, the client only sees a generic message
+func Internal(err error) *Error {
+	return &Error{Kind: KIND_INTERNAL, Code: CODE_INTERNAL, Message: "internal server error", Err: err}
+}
+
+// the app error in the chain of err, errors that are not app errors are internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when nothing is cached under the key
+var ErrMiss = errors.New("cache: miss")
+
+// Cache keeps values by key, next to plain values it keeps sets of keys so
+// related entries can be deleted together
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	// a zero expiration keeps the value until it is deleted
+	Set(ctx context.Context, key string, value string, expiration time.Duration) error
+	// returns how many of the keys existed
+	Delete(ctx context.Context, keys ...string) (int64, error)
+	// delete every key starting with the prefix, returns how many were deleted
+	DeleteByPrefix(ctx context.Context, prefix string) (int64, error)
+	AddToSet(ctx context.Context, key string, members ...string) error
+	SetMembers(ctx context.Context, key string) ([]string, error)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     string
+	set       map[string]bool
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache keeps the entries in a map of the process, for development and tests
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]*memoryEntry{}}
+}
+
+// the entry under the key, expired entries are removed on the way
+func (c *MemoryCache) entry(key string) *memoryEntry {
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+	if entry == nil || entry.set != nil {
+		return "", ErrMiss
+	}
+	return entry.value, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{value: value}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if c.entry(key) != nil {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *MemoryCache) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deleted int64
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) && c.entry(key) != nil {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *MemoryCache) AddToSet(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key)
+	if entry == nil || entry.set == nil {
+		entry = &memoryEntry{set: map[string]bool{}}
+		c.entries[key] = entry
+	}
+	for _, member := range members {
+		entry.set[member] = true
+	}
+	return nil
+}
+
+func (c *MemoryCache) SetMembers(ctx context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	members := []string{}
+	entry := c.entry(key)
+	if entry == nil {
+		return members, nil
+	}
+	for member := range entry.set {
+		members = append(members, member)
+	}
+	return members, nil
+}
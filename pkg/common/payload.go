@@ -1,18 +1,24 @@
 package common
 
 type Response struct {
-	Status  bool        `json:"status"`
-	Message string      `json:"messages"`
-	Meta    *Pagination `json:"meta,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Status  bool   `json:"status"`
+	Message string `json:"messages"`
+	// machine readable error code, only set on errors
+	Code  string      `json:"code,omitempty"`
+	Meta  *Pagination `json:"meta,omitempty"`
+	Error interface{} `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
 }
 
 type Pagination struct {
+	Total       int64 `json:"total"`
 	PerPage     int64 `json:"per_page"`
 	CurrentPage int64 `json:"current_page"`
 	LastPage    int64 `json:"last_page"`
 	IsLoadMore  bool  `json:"is_load_more"`
+	// only set on cursor pagination
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type PaginationRequest struct {
@@ -20,4 +26,8 @@ type PaginationRequest struct {
 	PerPage int64 `json:"perpage"`
 	Limit   int64
 	Offset  int64
+	// cursor pagination is used instead of the offset when the cursor param is given,
+	// an empty cursor starts from the first page
+	CursorMode bool
+	Cursor     string
 }
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// LogMailer does not send anything, the messages are logged and saved as .eml
+// files in the directory when it is set, for development and tests
+type LogMailer struct {
+	dir   string
+	from  string
+	count uint64
+}
+
+func NewLogMailer(dir string, from string) *LogMailer {
+	return &LogMailer{dir: dir, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Mail to %s: %s.", msg.To, msg.Subject)
+	if m.dir == "" {
+		return nil
+	}
+
+	err = os.MkdirAll(m.dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d.eml", now.Format("20060102150405"), atomic.AddUint64(&m.count, 1))
+	return os.WriteFile(filepath.Join(m.dir, name), msg.bytes(m.from, now.Format(time.RFC1123Z)), 0644)
+}
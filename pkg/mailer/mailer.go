@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+const (
+	DRIVER_SMTP = "smtp"
+	DRIVER_LOG  = "log"
+)
+
+var ErrInvalidHeader = errors.New("mail header must not contain a line break")
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text emails, sending stops when the context is done
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// the headers are written as they are so a line break would start a new header
+func (m *Message) validate() error {
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
+
+// the message in the internet message format, ready to be sent or saved as .eml
+func (m *Message) bytes(from string, date string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + m.To + "\r\n")
+	b.WriteString("Subject: " + m.Subject + "\r\n")
+	b.WriteString("Date: " + date + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer keeps the messages instead of sending them, for tests
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg *Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sent := *msg
+	m.messages = append(m.messages, &sent)
+	return nil
+}
+
+// the messages sent to the address, the oldest first
+func (m *MemoryMailer) Messages(to string) []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := []*Message{}
+	for _, msg := range m.messages {
+		if msg.To == to {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
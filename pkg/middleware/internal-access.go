@@ -1,13 +1,15 @@
 package middleware
 
 import (
-	"net/http"
+	"crypto/subtle"
 
-	"github.com/haikalvidya/go-article/pkg/common"
+	"github.com/haikalvidya/go-article/pkg/apperror"
 
 	"github.com/labstack/echo/v4"
 )
 
+var ErrInternalAccessDenied = apperror.Unauthorized("internal_access_denied", "Unauthorized")
+
 type InternalAccess struct {
 	secret string
 }
@@ -24,13 +26,10 @@ func (icm *InternalAccess) ValidateInternalAccess(next echo.HandlerFunc) echo.Ha
 		// get inernal secret from headers
 		internalToken := c.Request().Header.Get("x-internal-token")
 
-		// check if request doesn't have x-internal-token headers
-		if internalToken != icm.secret {
-			res := &common.Response{
-				Message: "Unauthorized",
-				Status:  false,
-			}
-			return c.JSON(http.StatusUnauthorized, res)
+		// check if request doesn't have x-internal-token headers,
+		// the internal api is closed when no secret is configured
+		if icm.secret == "" || subtle.ConstantTimeCompare([]byte(internalToken), []byte(icm.secret)) != 1 {
+			return ErrInternalAccessDenied
 		}
 		return next(c)
 	}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"time"
@@ -10,30 +12,48 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// SessionValidator is called for every valid token so revoked sessions are rejected
+type SessionValidator func(c echo.Context, userId string, sessionId string) error
+
 type Jwt struct {
-	AccessTokenExpiredHour int
-	Secret                 string
+	AccessTokenExpiredHour  int
+	RefreshTokenExpiredHour int
+	Secret                  string
+	// when set the tokens are signed with the active key of the set and the
+	// secret is not used
+	Keys             *JwtKeySet
+	SessionValidator SessionValidator
 }
 
-func NewJwt(expiredHour int, secret string) *Jwt {
+func NewJwt(accessExpiredHour int, refreshExpiredHour int, secret string, keys *JwtKeySet, sessionValidator SessionValidator) *Jwt {
 	return &Jwt{
-		AccessTokenExpiredHour: expiredHour,
-		Secret:                 secret,
+		AccessTokenExpiredHour:  accessExpiredHour,
+		RefreshTokenExpiredHour: refreshExpiredHour,
+		Secret:                  secret,
+		Keys:                    keys,
+		SessionValidator:        sessionValidator,
 	}
 }
 
 type jwtCustomClaims struct {
+	Role string `json:"role"`
 	jwt.StandardClaims
 }
 
-func (j *Jwt) GenerateToken(userId []byte) (string, error) {
+func (j *Jwt) GenerateToken(userId []byte, sessionId string, role string) (string, error) {
 	claims := &jwtCustomClaims{
-		jwt.StandardClaims{
+		Role: role,
+		StandardClaims: jwt.StandardClaims{
+			Id:        sessionId,
 			Subject:   string(userId),
 			ExpiresAt: time.Now().Add(time.Hour * time.Duration(j.AccessTokenExpiredHour)).Unix(),
 		},
 	}
 
+	if j.Keys != nil {
+		return j.signWithKey(claims)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	t, err := token.SignedString([]byte(j.Secret))
@@ -45,19 +65,69 @@ func (j *Jwt) GenerateToken(userId []byte) (string, error) {
 	return t, nil
 }
 
+// sign with the active key, the kid header tells the verifiers which key to use
+func (j *Jwt) signWithKey(claims jwt.Claims) (string, error) {
+	key := j.Keys.Active()
+
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return "", ErrUnsupportedAlg
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
+
+func (j *Jwt) verificationKey(t *jwt.Token) (interface{}, error) {
+	if j.Keys == nil {
+		if t.Method.Alg() != JWT_ALG_HS256 {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+		}
+		return []byte(j.Secret), nil
+	}
+
+	id, _ := t.Header["kid"].(string)
+	key, err := j.Keys.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
+
+// public keys other services can verify the tokens with, empty when the
+// tokens are signed with the shared secret
+func (j *Jwt) GetJWKS() *JWKS {
+	if j.Keys == nil {
+		return &JWKS{Keys: []*JWK{}}
+	}
+	return j.Keys.JWKS()
+}
+
+// refresh token is an opaque random string, the state is kept server side
+func (j *Jwt) GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (j *Jwt) GetRefreshTokenExpiration() time.Duration {
+	return time.Hour * time.Duration(j.RefreshTokenExpiredHour)
+}
+
 func (j *Jwt) ValidateJWT() echo.MiddlewareFunc {
 
 	JWTConfig := middleware.JWTConfig{
 		TokenLookup: "header:" + echo.HeaderAuthorization,
 		ParseTokenFunc: func(auth string, c echo.Context) (interface{}, error) {
-			keyFunc := func(t *jwt.Token) (interface{}, error) {
-				if t.Method.Alg() != "HS256" {
-					return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
-				}
-				return []byte(j.Secret), nil
-			}
-
-			token, err := jwt.Parse(auth, keyFunc)
+			token, err := jwt.Parse(auth, j.verificationKey)
 
 			if err != nil {
 				return nil, err
@@ -65,6 +135,15 @@ func (j *Jwt) ValidateJWT() echo.MiddlewareFunc {
 			if !token.Valid {
 				return nil, errors.New("invalid token")
 			}
+
+			if j.SessionValidator != nil {
+				claims := token.Claims.(jwt.MapClaims)
+				userId, _ := claims["sub"].(string)
+				sessionId, _ := claims["jti"].(string)
+				if err := j.SessionValidator(c, userId, sessionId); err != nil {
+					return nil, err
+				}
+			}
 			return token, nil
 		},
 	}
@@ -81,3 +160,13 @@ func (j *Jwt) GetUserIdFromJwt(c echo.Context) string {
 	userId := j.GetJWTClaims(c)["sub"].(string)
 	return userId
 }
+
+func (j *Jwt) GetSessionIdFromJwt(c echo.Context) string {
+	sessionId, _ := j.GetJWTClaims(c)["jti"].(string)
+	return sessionId
+}
+
+func (j *Jwt) GetRoleFromJwt(c echo.Context) string {
+	role, _ := j.GetJWTClaims(c)["role"].(string)
+	return role
+}
@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JWT_ALG_HS256 = "HS256"
+	JWT_ALG_RS256 = "RS256"
+	JWT_ALG_EDDSA = "EdDSA"
+
+	jwtRSAKeySize = 2048
+	// the key ids start with the time the key was made so the newest sorts last
+	jwtKeyIDTimeFormat = "20060102T150405Z"
+	// the key directory is read again after this so rotations are picked up
+	jwtKeyReloadInterval = time.Minute
+	// an unknown key id reloads the directory early, at most this often
+	jwtKeyMissReloadInterval = 5 * time.Second
+)
+
+var (
+	ErrNoJwtKeys      = errors.New("no jwt keys found, create one with the jwt rotate command")
+	ErrUnknownJwtKey  = errors.New("unknown jwt key id")
+	ErrUnsupportedAlg = errors.New("unsupported jwt algorithm")
+)
+
+type JwtKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	CreatedAt  time.Time
+}
+
+// JwtKeySet holds the keys of a directory with one pem file per key, named after
+// the key id, the newest key signs and every key can verify
+type JwtKeySet struct {
+	mu       sync.RWMutex
+	dir      string
+	keys     map[string]*JwtKey
+	active   *JwtKey
+	loadedAt time.Time
+}
+
+func NewJwtKeySet(dir string) (*JwtKeySet, error) {
+	set := &JwtKeySet{dir: dir}
+
+	err := set.load()
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *JwtKeySet) load() error {
+	keys, err := readJwtKeys(s.dir)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return ErrNoJwtKeys
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = map[string]*JwtKey{}
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	s.active = keys[len(keys)-1]
+	s.loadedAt = time.Now()
+	return nil
+}
+
+// read the directory again when it was loaded too long ago, the keys loaded
+// before are kept when that fails
+func (s *JwtKeySet) refresh() {
+	s.reloadOlderThan(jwtKeyReloadInterval)
+}
+
+func (s *JwtKeySet) reloadOlderThan(age time.Duration) {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > age
+	s.mu.RUnlock()
+
+	if stale {
+		s.load()
+	}
+}
+
+// the key new tokens are signed with
+func (s *JwtKeySet) Active() *JwtKey {
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+// the key of a token, the directory is read again when the key id is not known
+// yet since another server may have signed with a key made after the last load
+func (s *JwtKeySet) Key(id string) (*JwtKey, error) {
+	s.refresh()
+
+	key := s.lookup(id)
+	if key == nil {
+		s.reloadOlderThan(jwtKeyMissReloadInterval)
+		key = s.lookup(id)
+	}
+	if key == nil {
+		return nil, ErrUnknownJwtKey
+	}
+	return key, nil
+}
+
+func (s *JwtKeySet) lookup(id string) *JwtKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[id]
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// rsa
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// the public keys in the json web key set format, newest first
+func (s *JwtKeySet) JWKS() *JWKS {
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := &JWKS{Keys: []*JWK{}}
+	for _, key := range s.keys {
+		res.Keys = append(res.Keys, key.JWK())
+	}
+	sort.Slice(res.Keys, func(a, b int) bool {
+		return res.Keys[a].KeyID > res.Keys[b].KeyID
+	})
+	return res
+}
+
+func (k *JwtKey) JWK() *JWK {
+	jwk := &JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+
+	switch public := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// the keys of the directory sorted from oldest to newest
+func readJwtKeys(dir string) ([]*JwtKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []*JwtKey{}
+	for _, file := range files {
+		key, err := readJwtKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].ID < keys[b].ID
+	})
+	return keys, nil
+}
+
+func readJwtKey(file string) (*JwtKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("not a pem file")
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strings.TrimSuffix(filepath.Base(file), ".pem")
+	createdAt, err := time.Parse(jwtKeyIDTimeFormat, strings.SplitN(id, "-", 2)[0])
+	if err != nil {
+		return nil, fmt.Errorf("key id %q does not start with the creation time", id)
+	}
+
+	key := &JwtKey{ID: id, PrivateKey: private, CreatedAt: createdAt}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = JWT_ALG_RS256
+		key.PublicKey = &private.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm = JWT_ALG_EDDSA
+		key.PublicKey = private.Public()
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+	return key, nil
+}
+
+// make a new key in the directory, it signs the new tokens once the servers reload the keys
+func GenerateJwtKey(dir string, algorithm string) (*JwtKey, error) {
+	var private crypto.PrivateKey
+	var err error
+	switch algorithm {
+	case JWT_ALG_RS256:
+		private, err = rsa.GenerateKey(rand.Reader, jwtRSAKeySize)
+	case JWT_ALG_EDDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	id := time.Now().UTC().Format(jwtKeyIDTimeFormat) + "-" + hex.EncodeToString(suffix)
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	file := filepath.Join(dir, id+".pem")
+	err = os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return readJwtKey(file)
+}
+
+// delete the keys that were replaced by a newer key more than retention ago,
+// the tokens they signed have expired by then, returns the deleted key ids
+func PruneJwtKeys(dir string, retention time.Duration) ([]string, error) {
+	keys, err := readJwtKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []string{}
+	for i := 0; i < len(keys)-1; i++ {
+		if time.Since(keys[i+1].CreatedAt) <= retention {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, keys[i].ID+".pem"))
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, keys[i].ID)
+	}
+	return deleted, nil
+}
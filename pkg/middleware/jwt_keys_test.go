@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// make a key that looks like it was made at the time, the creation time is
+// read from the key id in the file name
+func generateJwtKeyAt(t *testing.T, dir string, algorithm string, at time.Time) *JwtKey {
+	t.Helper()
+
+	key, err := GenerateJwtKey(dir, algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := at.UTC().Format(jwtKeyIDTimeFormat) + "-" + algorithm
+	err = os.Rename(filepath.Join(dir, key.ID+".pem"), filepath.Join(dir, id+".pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err = readJwtKey(filepath.Join(dir, id+".pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// read the directory on the next use like the servers do once the reload interval passed
+func expireKeySet(set *JwtKeySet) {
+	set.mu.Lock()
+	set.loadedAt = time.Time{}
+	set.mu.Unlock()
+}
+
+// the error of the key lookup, jwt wraps it without Unwrap
+func verifyToken(j *Jwt, token string) error {
+	_, err := jwt.Parse(token, j.verificationKey)
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Inner != nil {
+		return validationErr.Inner
+	}
+	return err
+}
+
+func TestJwtKeyRotation(t *testing.T) {
+	for _, algorithm := range []string{JWT_ALG_RS256, JWT_ALG_EDDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			dir := t.TempDir()
+			now := time.Now()
+
+			old := generateJwtKeyAt(t, dir, algorithm, now.Add(-3*time.Hour))
+			set, err := NewJwtKeySet(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j := NewJwt(1, 24, "", set, nil)
+
+			token, err := j.GenerateToken([]byte("user"), "session", "reader")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the new key signs once the directory is read again, the old tokens still verify
+			rotated := generateJwtKeyAt(t, dir, algorithm, now.Add(-2*time.Hour))
+			expireKeySet(set)
+			if got := set.Active().ID; got != rotated.ID {
+				t.Fatalf("active key = %s, want the rotated key %s", got, rotated.ID)
+			}
+			err = verifyToken(j, token)
+			if err != nil {
+				t.Fatalf("token of the previous key: %v", err)
+			}
+
+			newToken, err := j.GenerateToken([]byte("user"), "session", "reader")
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _ := jwt.Parse(newToken, j.verificationKey)
+			if parsed == nil || parsed.Header["kid"] != rotated.ID {
+				t.Fatalf("new token is not signed with the rotated key")
+			}
+
+			// the old key is kept while tokens it signed can still be valid
+			deleted, err := PruneJwtKeys(dir, 3*time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deleted) != 0 {
+				t.Fatalf("pruned %v within the retention", deleted)
+			}
+
+			deleted, err = PruneJwtKeys(dir, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deleted) != 1 || deleted[0] != old.ID {
+				t.Fatalf("pruned %v, want [%s]", deleted, old.ID)
+			}
+
+			expireKeySet(set)
+			err = verifyToken(j, token)
+			if !errors.Is(err, ErrUnknownJwtKey) {
+				t.Errorf("token of the pruned key err = %v, want %v", err, ErrUnknownJwtKey)
+			}
+			err = verifyToken(j, newToken)
+			if err != nil {
+				t.Errorf("token of the active key: %v", err)
+			}
+		})
+	}
+}
+
+func TestJwtKeyRejectsOtherAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	generateJwtKeyAt(t, dir, JWT_ALG_RS256, time.Now())
+	set, err := NewJwtKeySet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := NewJwt(1, 24, "secret", set, nil)
+
+	// a token signed with the shared secret is not accepted once keys are used
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verifyToken(j, token) == nil {
+		t.Error("a token signed with the secret was accepted")
+	}
+}
+
+func TestPruneKeepsActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	only := generateJwtKeyAt(t, dir, JWT_ALG_EDDSA, time.Now().Add(-48*time.Hour))
+
+	deleted, err := PruneJwtKeys(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("pruned %v, the only key %s has to stay", deleted, only.ID)
+	}
+}
+
+func TestJWKSOnlyHasPublicKeys(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := generateJwtKeyAt(t, dir, JWT_ALG_RS256, time.Now().Add(-time.Hour))
+	edKey := generateJwtKeyAt(t, dir, JWT_ALG_EDDSA, time.Now())
+
+	set, err := NewJwtKeySet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(set.JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	err = json.Unmarshal(data, &jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("got %d keys, want 2", len(jwks.Keys))
+	}
+
+	public := map[string]bool{"kty": true, "kid": true, "use": true, "alg": true, "n": true, "e": true, "crv": true, "x": true}
+	for _, key := range jwks.Keys {
+		for field := range key {
+			if !public[field] {
+				t.Errorf("key %s has the field %q", key["kid"], field)
+			}
+		}
+	}
+
+	// newest first
+	ed, rsaJWK := jwks.Keys[0], jwks.Keys[1]
+	if ed["kid"] != edKey.ID || ed["kty"] != "OKP" || ed["crv"] != "Ed25519" || ed["alg"] != JWT_ALG_EDDSA {
+		t.Errorf("ed25519 key = %v", ed)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(ed["x"])
+	if !edKey.PublicKey.(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Error("the ed25519 key is not the public key")
+	}
+
+	if rsaJWK["kid"] != rsaKey.ID || rsaJWK["kty"] != "RSA" || rsaJWK["alg"] != JWT_ALG_RS256 {
+		t.Errorf("rsa key = %v", rsaJWK)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK["n"])
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK["e"])
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if !rsaKey.PublicKey.(*rsa.PublicKey).Equal(publicKey) {
+		t.Error("the rsa key is not the public key")
+	}
+}
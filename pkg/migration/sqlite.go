@@ -0,0 +1,7 @@
+//go:build cgo
+// +build cgo
+
+package migration
+
+// the sqlite driver of dbmate needs cgo, without it sqlite can not be migrated
+import _ "github.com/amacneil/dbmate/pkg/driver/sqlite"
@@ -0,0 +1,265 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haikalvidya/go-article/pkg/utils"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// pkce verifiers and nonces are random url safe strings of this many bytes
+	randomSize = 32
+
+	defaultHTTPTimeout = 10 * time.Second
+	// how long a failed discovery is remembered before it is tried again
+	discoveryRetryInterval = 10 * time.Second
+)
+
+var (
+	ErrDiscovery       = errors.New("oidc discovery failed")
+	ErrExchange        = errors.New("oidc code exchange failed")
+	ErrInvalidIDToken  = errors.New("invalid oidc id token")
+	ErrIssuerMismatch  = errors.New("oidc issuer does not match the configured issuer")
+	ErrNoIDToken       = errors.New("oidc token response has no id token")
+	ErrUnknownSignKey  = errors.New("oidc id token is signed with an unknown key")
+	ErrNonceMismatch   = errors.New("oidc id token nonce does not match")
+	ErrAudienceInvalid = errors.New("oidc id token is not for this client")
+)
+
+type Config struct {
+	// the issuer url, the discovery document is read from issuer + /.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// openid is always asked for
+	Scopes []string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is a client of one openid connect provider using the authorization
+// code flow with pkce, the discovery document is loaded on first use so the
+// service starts when the provider is down
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *discovery
+	discoveryErr error
+	discoveredAt time.Time
+
+	keys *keySet
+}
+
+// the client is used for every call to the provider, nil uses a client with a timeout
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	p := &Provider{config: cfg, client: client}
+	p.keys = newKeySet(p)
+	return p
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type tokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// IDToken holds the claims of a verified id token that are used to find the user
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// random verifier and its S256 challenge for pkce
+func NewPKCE() (verifier string, challenge string, err error) {
+	verifier, err = utils.GenerateRandomToken(randomSize)
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func NewNonce() (string, error) {
+	return utils.GenerateRandomToken(randomSize)
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	if p.discoveryErr != nil && time.Since(p.discoveredAt) < discoveryRetryInterval {
+		return nil, p.discoveryErr
+	}
+
+	doc, err := p.discover(ctx)
+	// a cancelled request says nothing about the provider so it is not remembered
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+
+	p.discovery, p.discoveryErr = doc, err
+	p.discoveredAt = time.Now()
+	return p.discovery, p.discoveryErr
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrDiscovery, res.StatusCode)
+	}
+
+	doc := &discovery{}
+	err = json.NewDecoder(res.Body).Decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, ErrIssuerMismatch
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+	return doc, nil
+}
+
+// the url of the provider login page, the user comes back to the redirect url
+// with the code and the state
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range p.config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	link, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	for key, values := range link.Query() {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// trade the code from the redirect for the tokens
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (*Token, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		tokenErr := &tokenError{}
+		json.Unmarshal(body, tokenErr)
+		return nil, fmt.Errorf("%w: status %d %s %s", ErrExchange, res.StatusCode, tokenErr.Error, tokenErr.ErrorDescription)
+	}
+
+	token := &Token{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+	return token, nil
+}
@@ -0,0 +1,214 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/haikalvidya/go-article/pkg/oidc"
+	"github.com/haikalvidya/go-article/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost/oidc/callback"
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+
+	server, err := oidctest.NewServer("client", "client-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	server.SetUser(oidctest.User{Subject: "subject", Email: "user@example.com", EmailVerified: true, Name: "User"})
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       server.Issuer,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}, nil)
+	return server, provider
+}
+
+// run the login at the provider and return the code with the nonce and pkce verifier it was started with
+func authorize(t *testing.T, server *oidctest.Server, provider *oidc.Provider) (code string, nonce string, verifier string) {
+	t.Helper()
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err = oidc.NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", nonce, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != "state" {
+		t.Fatalf("state = %q, want %q", state, "state")
+	}
+	return code, nonce, verifier
+}
+
+func TestDiscovery(t *testing.T) {
+	server, provider := newTestProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", oidc.PKCEChallenge("verifier"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := link.Scheme+"://"+link.Host+link.Path, server.Issuer+"/authorize"; got != want {
+		t.Errorf("authorization endpoint = %q, want %q", got, want)
+	}
+
+	query := link.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          redirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        oidc.PKCEChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestDiscoveryFailures(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/other/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"issuer":"https://someone.else","authorization_endpoint":"a","token_endpoint":"t","jwks_uri":"j"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		issuer string
+		err    error
+	}{
+		{name: "no discovery document", issuer: server.URL + "/missing", err: oidc.ErrDiscovery},
+		{name: "issuer of another provider", issuer: server.URL + "/other", err: oidc.ErrIssuerMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := oidc.NewProvider(oidc.Config{Issuer: tt.issuer, ClientID: "client"}, nil)
+			_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDiscoveryCancelledIsNotRemembered(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := provider.AuthCodeURL(ctx, "state", "nonce", "challenge")
+	if err == nil {
+		t.Fatal("discovery with a cancelled context succeeded")
+	}
+
+	_, err = provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if err != nil {
+		t.Errorf("discovery after a cancelled one: %v", err)
+	}
+}
+
+func TestExchangeWithPKCE(t *testing.T) {
+	server, provider := newTestProvider(t)
+
+	code, nonce, verifier := authorize(t, server, provider)
+	token, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idToken, err := provider.Verify(context.Background(), token.IDToken, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := oidc.IDToken{Issuer: server.Issuer, Subject: "subject", Email: "user@example.com", EmailVerified: true, Name: "User"}
+	if *idToken != want {
+		t.Errorf("id token = %+v, want %+v", *idToken, want)
+	}
+
+	// the code works once
+	_, err = provider.Exchange(context.Background(), code, verifier)
+	if !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("second exchange err = %v, want %v", err, oidc.ErrExchange)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	server, provider := newTestProvider(t)
+
+	code, _, _ := authorize(t, server, provider)
+	_, err := provider.Exchange(context.Background(), code, "another-verifier")
+	if !errors.Is(err, oidc.ErrExchange) || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("err = %v, want an invalid_grant %v", err, oidc.ErrExchange)
+	}
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		nonce  string
+		err    error
+	}{
+		{name: "other nonce", nonce: "another-nonce", err: oidc.ErrNonceMismatch},
+		{name: "other audience", claims: map[string]interface{}{"aud": "another-client"}, err: oidc.ErrAudienceInvalid},
+		{name: "other issuer", claims: map[string]interface{}{"iss": "https://someone.else"}, err: oidc.ErrIssuerMismatch},
+		{name: "expired", claims: map[string]interface{}{"exp": 1}, err: oidc.ErrInvalidIDToken},
+		{name: "no subject", claims: map[string]interface{}{"sub": ""}, err: oidc.ErrInvalidIDToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, provider := newTestProvider(t)
+			server.SetClaims(tt.claims)
+
+			code, nonce, verifier := authorize(t, server, provider)
+			token, err := provider.Exchange(context.Background(), code, verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+			_, err = provider.Verify(context.Background(), token.IDToken, nonce)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// the id token has to be signed with a key of the provider, the shared secret
+// algorithms and none are never accepted
+var allowedSigningAlgs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// check the signature, issuer, audience, expiry and nonce of the id token
+func (p *Provider) Verify(ctx context.Context, rawIDToken string, nonce string) (*IDToken, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &jwt.Parser{ValidMethods: allowedSigningAlgs}
+	token, err := parser.Parse(rawIDToken, func(t *jwt.Token) (interface{}, error) {
+		id, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, id)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+
+	if !claims.VerifyIssuer(doc.Issuer, true) {
+		return nil, ErrIssuerMismatch
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, ErrAudienceInvalid
+	}
+	// the exp claim is optional for the jwt library but required for id tokens
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidIDToken)
+	}
+	if claimString(claims, "nonce") != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	subject := claimString(claims, "sub")
+	if subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	return &IDToken{
+		Issuer:        doc.Issuer,
+		Subject:       subject,
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "email_verified"),
+		Name:          claimString(claims, "name"),
+	}, nil
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// some providers send booleans as strings
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+	return false
+}
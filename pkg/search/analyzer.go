@@ -0,0 +1,101 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	LANGUAGE_ENGLISH    = "en"
+	LANGUAGE_INDONESIAN = "id"
+)
+
+var englishStopwords = toSet(
+	"a", "about", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have",
+	"he", "her", "his", "i", "if", "in", "into", "is", "it", "its", "not", "of", "on", "or", "our",
+	"she", "so", "that", "the", "their", "them", "then", "there", "these", "they", "this", "to",
+	"was", "we", "were", "what", "when", "which", "who", "will", "with", "you", "your",
+)
+
+var indonesianStopwords = toSet(
+	"ada", "adalah", "agar", "akan", "aku", "anda", "atau", "bagi", "bahwa", "beberapa", "belum",
+	"bisa", "dalam", "dan", "dari", "dengan", "di", "dia", "harus", "hingga", "ia", "ini", "itu",
+	"jika", "juga", "kami", "kamu", "karena", "ke", "kita", "lagi", "lebih", "mereka", "nya", "oleh",
+	"pada", "saat", "saja", "sangat", "saya", "sebagai", "sudah", "tersebut", "tetapi", "tidak",
+	"untuk", "yaitu", "yang",
+)
+
+type token struct {
+	Term     string
+	Position int
+}
+
+// guess the language of the text from the stopwords it uses, english wins a tie
+func detectLanguage(words []string) string {
+	english, indonesian := 0, 0
+	for _, word := range words {
+		if englishStopwords[word] {
+			english++
+		}
+		if indonesianStopwords[word] {
+			indonesian++
+		}
+	}
+	if indonesian > english {
+		return LANGUAGE_INDONESIAN
+	}
+	return LANGUAGE_ENGLISH
+}
+
+// split the text into stemmed terms, stopwords are dropped but still take a
+// position so phrases are matched on the original word distance
+func analyze(text string, language string, offset int) []token {
+	tokens := []token{}
+	for i, word := range words(text) {
+		if isStopword(word) {
+			continue
+		}
+		tokens = append(tokens, token{Term: stem(word, language), Position: offset + i})
+	}
+	return tokens
+}
+
+// the terms a query word can be indexed under, the language of the query is unknown
+// so the word is stemmed for every language
+func variants(word string) []string {
+	english := stem(word, LANGUAGE_ENGLISH)
+	indonesian := stem(word, LANGUAGE_INDONESIAN)
+	if english == indonesian {
+		return []string{english}
+	}
+	return []string{english, indonesian}
+}
+
+func stem(word string, language string) string {
+	if language == LANGUAGE_INDONESIAN {
+		return stemIndonesian(word)
+	}
+	return stemEnglish(word)
+}
+
+func isStopword(word string) bool {
+	return englishStopwords[word] || indonesianStopwords[word]
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
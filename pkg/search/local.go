@@ -0,0 +1,402 @@
+package search
+
+import (
+	"context"
+	"encoding/gob"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// bm25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// gap between the title and body positions so a phrase never spans both
+const titleBodyGap = 100
+
+type localDocument struct {
+	Length int
+	Terms  []string
+}
+
+// the persisted part of the index
+type localIndexData struct {
+	// term -> document id -> positions of the term in the document
+	Postings    map[string]map[int][]int
+	Documents   map[int]*localDocument
+	TotalLength int
+}
+
+// LocalIndex is an inverted index kept in memory and saved to a file after every
+// change, another process writing the same file is picked up on the next use.
+// changes hold a lock on the file so two processes never lose each other's writes.
+// without a path the index is never saved, for tests
+type LocalIndex struct {
+	mu      sync.Mutex
+	path    string
+	size    int64
+	modTime time.Time
+	data    *localIndexData
+}
+
+func NewLocalIndex(path string) (*LocalIndex, error) {
+	index := &LocalIndex{path: path, data: newLocalIndexData()}
+
+	err := index.reload()
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func newLocalIndexData() *localIndexData {
+	return &localIndexData{
+		Postings:  map[string]map[int][]int{},
+		Documents: map[int]*localDocument{},
+	}
+}
+
+func (i *LocalIndex) Index(ctx context.Context, doc *Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	unlock, err := i.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = i.reload()
+	if err != nil {
+		return err
+	}
+
+	i.data.remove(doc.ID)
+	i.data.add(doc)
+	return i.save()
+}
+
+func (i *LocalIndex) Delete(ctx context.Context, id int) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	unlock, err := i.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = i.reload()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := i.data.Documents[id]; !ok {
+		return nil
+	}
+
+	i.data.remove(id)
+	return i.save()
+}
+
+func (i *LocalIndex) Rebuild(ctx context.Context, docs []*Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	unlock, err := i.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	i.data = newLocalIndexData()
+	for _, doc := range docs {
+		i.data.add(doc)
+	}
+	return i.save()
+}
+
+func (i *LocalIndex) Search(ctx context.Context, query *Query, limit int) ([]*Hit, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	err := i.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return i.data.search(query, limit), nil
+}
+
+// lock the index file for a change, the index is reloaded and saved while the lock is held
+func (i *LocalIndex) lock() (func(), error) {
+	if i.path == "" {
+		return func() {}, nil
+	}
+	return lockFile(i.path + ".lock")
+}
+
+// load the index file when it changed since it was last read, the size is compared
+// too because two writes within the resolution of the file system clock have the same time
+func (i *LocalIndex) reload() error {
+	if i.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(i.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() == i.size && info.ModTime().Equal(i.modTime) {
+		return nil
+	}
+
+	file, err := os.Open(i.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data := newLocalIndexData()
+	err = gob.NewDecoder(file).Decode(data)
+	if err != nil {
+		return err
+	}
+
+	i.data = data
+	i.size = info.Size()
+	i.modTime = info.ModTime()
+	return nil
+}
+
+// write the index to a temporary file first so readers never see half of it
+func (i *LocalIndex) save() error {
+	if i.path == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(i.path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(i.path), filepath.Base(i.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	err = gob.NewEncoder(file).Encode(i.data)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(file.Name(), i.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(i.path)
+	if err != nil {
+		return err
+	}
+	i.size = info.Size()
+	i.modTime = info.ModTime()
+	return nil
+}
+
+func (d *localIndexData) add(doc *Document) {
+	language := detectLanguage(append(words(doc.Title), words(doc.Body)...))
+
+	tokens := analyze(doc.Title, language, 0)
+	tokens = append(tokens, analyze(doc.Body, language, len(words(doc.Title))+titleBodyGap)...)
+
+	document := &localDocument{Length: len(tokens)}
+	for _, token := range tokens {
+		postings, ok := d.Postings[token.Term]
+		if !ok {
+			postings = map[int][]int{}
+			d.Postings[token.Term] = postings
+		}
+		if _, ok := postings[doc.ID]; !ok {
+			document.Terms = append(document.Terms, token.Term)
+		}
+		postings[doc.ID] = append(postings[doc.ID], token.Position)
+	}
+
+	d.Documents[doc.ID] = document
+	d.TotalLength += document.Length
+}
+
+func (d *localIndexData) remove(id int) {
+	document, ok := d.Documents[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range document.Terms {
+		delete(d.Postings[term], id)
+		if len(d.Postings[term]) == 0 {
+			delete(d.Postings, term)
+		}
+	}
+
+	delete(d.Documents, id)
+	d.TotalLength -= document.Length
+}
+
+// positions of a query word in a document, looked up under every stem of the word
+func (d *localIndexData) positions(word string, id int) []int {
+	res := []int{}
+	for _, term := range variants(word) {
+		res = append(res, d.Postings[term][id]...)
+	}
+	return res
+}
+
+// documents containing the word under any of its stems
+func (d *localIndexData) documents(word string) map[int]bool {
+	res := map[int]bool{}
+	for _, term := range variants(word) {
+		for id := range d.Postings[term] {
+			res[id] = true
+		}
+	}
+	return res
+}
+
+func (d *localIndexData) containsPhrase(phrase []string, id int) bool {
+	// positions where the phrase would start, stopwords are not indexed so they are skipped
+	var starts map[int]bool
+	for n, word := range phrase {
+		if isStopword(word) {
+			continue
+		}
+
+		next := map[int]bool{}
+		for _, position := range d.positions(word, id) {
+			if starts == nil || starts[position-n] {
+				next[position-n] = true
+			}
+		}
+		starts = next
+
+		if len(starts) == 0 {
+			return false
+		}
+	}
+	return len(starts) > 0
+}
+
+func (d *localIndexData) search(query *Query, limit int) []*Hit {
+	// every word of the terms and phrases has to be in the document
+	required := [][]string{}
+	for _, term := range query.Terms {
+		required = append(required, []string{term})
+	}
+	for _, phrase := range query.Phrases {
+		required = append(required, words(phrase))
+	}
+
+	var candidates map[int]bool
+	scored := []string{}
+	for _, group := range required {
+		for _, word := range group {
+			if isStopword(word) {
+				continue
+			}
+			scored = append(scored, word)
+
+			found := d.documents(word)
+			if candidates == nil {
+				candidates = found
+				continue
+			}
+			for id := range candidates {
+				if !found[id] {
+					delete(candidates, id)
+				}
+			}
+		}
+	}
+
+	hits := []*Hit{}
+	for id := range candidates {
+		if !d.matches(query, id) {
+			continue
+		}
+		hits = append(hits, &Hit{ID: id, Score: d.score(scored, id)})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID > hits[b].ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// check the phrases and exclusions of the query against a candidate document
+func (d *localIndexData) matches(query *Query, id int) bool {
+	for _, phrase := range query.Phrases {
+		if !d.containsPhrase(words(phrase), id) {
+			return false
+		}
+	}
+
+	for _, excluded := range query.Excluded {
+		excludedWords := words(excluded)
+		if len(excludedWords) == 1 && !isStopword(excludedWords[0]) && d.documents(excludedWords[0])[id] {
+			return false
+		}
+		if len(excludedWords) > 1 && d.containsPhrase(excludedWords, id) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *localIndexData) score(queryWords []string, id int) float64 {
+	total := float64(len(d.Documents))
+	averageLength := float64(d.TotalLength) / total
+	length := float64(d.Documents[id].Length)
+
+	score := 0.0
+	for _, word := range queryWords {
+		// the stem that scores best counts when a word has several stems
+		best := 0.0
+		for _, term := range variants(word) {
+			frequency := float64(len(d.Postings[term][id]))
+			if frequency == 0 {
+				continue
+			}
+
+			documentFrequency := float64(len(d.Postings[term]))
+			idf := math.Log(1 + (total-documentFrequency+0.5)/(documentFrequency+0.5))
+			termScore := idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*length/averageLength))
+			best = math.Max(best, termScore)
+		}
+		score += best
+	}
+	return score
+}
@@ -0,0 +1,204 @@
+package search
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func mustIndex(t *testing.T, index *LocalIndex, docs ...*Document) {
+	t.Helper()
+	for _, doc := range docs {
+		err := index.Index(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("Index(%d): %v", doc.ID, err)
+		}
+	}
+}
+
+func searchIDs(t *testing.T, index *LocalIndex, text string) []int {
+	t.Helper()
+	hits, err := index.Search(context.Background(), ParseQuery(text), 0)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", text, err)
+	}
+	ids := []int{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func equalIDs(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLocalIndexBM25(t *testing.T) {
+	index, err := NewLocalIndex("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustIndex(t, index,
+		&Document{ID: 1, Title: "Cooking", Body: "golang once in a long text about recipes soup bread salad"},
+		&Document{ID: 2, Title: "Golang", Body: "golang golang tips"},
+		&Document{ID: 3, Title: "Notes", Body: "golang"},
+		&Document{ID: 4, Title: "Notes", Body: "rust channels"},
+		&Document{ID: 5, Title: "Notes", Body: "golang channels"},
+	)
+
+	// more occurrences rank higher, and for the same frequency the shorter document wins
+	if got, want := searchIDs(t, index, "golang"), []int{2, 3, 5, 1}; !equalIDs(got, want) {
+		t.Errorf("golang = %v, want %v", got, want)
+	}
+
+	// every term is required
+	if got, want := searchIDs(t, index, "golang channels"), []int{5}; !equalIDs(got, want) {
+		t.Errorf("golang channels = %v, want %v", got, want)
+	}
+
+	// the score matches the bm25 formula
+	hits, err := index.Search(context.Background(), ParseQuery("rust"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := index.data
+	total := float64(len(data.Documents))
+	averageLength := float64(data.TotalLength) / total
+	idf := math.Log(1 + (total-1+0.5)/(1+0.5))
+	length := float64(data.Documents[4].Length)
+	want := idf * (bm25K1 + 1) / (1 + bm25K1*(1-bm25B+bm25B*length/averageLength))
+	if len(hits) != 1 || math.Abs(hits[0].Score-want) > 1e-9 {
+		t.Errorf("rust = %+v, want one hit scoring %v", hits, want)
+	}
+
+	// a rare term is worth more than a common one
+	if rare, common := data.score([]string{"rust"}, 4), data.score([]string{"channels"}, 4); rare <= common {
+		t.Errorf("rare term scores %v, common term %v", rare, common)
+	}
+}
+
+func TestLocalIndexQueries(t *testing.T) {
+	index, err := NewLocalIndex("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustIndex(t, index,
+		&Document{ID: 1, Title: "Error handling in Go", Body: "wrap the errors"},
+		&Document{ID: 2, Title: "Handling errors", Body: "an error is a value in java"},
+		&Document{ID: 3, Title: "Menulis kode", Body: "saya sedang menulis buku tentang kode yang bersih"},
+	)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: `"error handling"`, want: []int{1}},
+		{query: "error -java", want: []int{1}},
+		{query: `error -"error handling"`, want: []int{2}},
+		// stems match the other forms of the word
+		{query: "handled", want: []int{2, 1}},
+		{query: "tulis", want: []int{3}},
+		{query: "-java", want: []int{}},
+		{query: "the", want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := searchIDs(t, index, tt.query); !equalIDs(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	err = index.Delete(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := searchIDs(t, index, `"error handling"`); len(got) != 0 {
+		t.Errorf("deleted document is still found: %v", got)
+	}
+}
+
+func TestLocalIndexSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "search.gob")
+
+	first, err := NewLocalIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewLocalIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustIndex(t, first, &Document{ID: 1, Title: "golang", Body: "first"})
+	mustIndex(t, second, &Document{ID: 2, Title: "golang", Body: "second and a longer body"})
+
+	// the second write keeps the document of the first process
+	if got, want := searchIDs(t, first, "golang"), []int{1, 2}; !equalIDs(got, want) {
+		t.Errorf("first process sees %v, want %v", got, want)
+	}
+
+	// a write within the same clock tick is picked up from the size
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustIndex(t, second, &Document{ID: 3, Title: "golang", Body: "third"})
+	err = os.Chtimes(path, info.ModTime(), info.ModTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := searchIDs(t, first, "golang"), []int{3, 1, 2}; !equalIDs(got, want) {
+		t.Errorf("first process sees %v after a write with the same time, want %v", got, want)
+	}
+}
+
+func TestLocalIndexConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.gob")
+
+	indexes := []*LocalIndex{}
+	for n := 0; n < 4; n++ {
+		index, err := NewLocalIndex(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		indexes = append(indexes, index)
+	}
+
+	var wg sync.WaitGroup
+	for n, index := range indexes {
+		wg.Add(1)
+		go func(n int, index *LocalIndex) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				err := index.Index(context.Background(), &Document{ID: n*10 + i + 1, Title: "golang"})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(n, index)
+	}
+	wg.Wait()
+
+	reader, err := NewLocalIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := searchIDs(t, reader, "golang"); len(got) != 40 {
+		t.Errorf("found %d documents, want 40", len(got))
+	}
+}
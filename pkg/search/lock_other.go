@@ -0,0 +1,8 @@
+//go:build !unix
+
+package search
+
+// file locks are only taken on unix, elsewhere a single process should use the index
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// take an exclusive lock on a file next to the index so processes sharing the
+// index do not overwrite each other's changes, the lock is held until unlock is called
+func lockFile(path string) (func(), error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
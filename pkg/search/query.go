@@ -0,0 +1,203 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query is a parsed search text, words and "quoted phrases" are required
+// and words or phrases prefixed with - exclude the articles containing them
+type Query struct {
+	Terms    []string
+	Phrases  []string
+	Excluded []string
+}
+
+func ParseQuery(text string) *Query {
+	query := &Query{}
+
+	for len(text) > 0 {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			break
+		}
+
+		exclude := strings.HasPrefix(text, "-")
+		if exclude {
+			text = text[1:]
+		}
+
+		var part string
+		phrase := strings.HasPrefix(text, `"`)
+		if phrase {
+			end := strings.Index(text[1:], `"`)
+			if end < 0 {
+				// unterminated quote, the rest of the text is the phrase
+				part, text = text[1:], ""
+			} else {
+				part, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(text, unicode.IsSpace)
+			if end < 0 {
+				end = len(text)
+			}
+			part, text = text[:end], text[end:]
+		}
+
+		words := words(part)
+		if len(words) == 0 {
+			continue
+		}
+
+		switch {
+		case exclude:
+			query.Excluded = append(query.Excluded, strings.Join(words, " "))
+		case phrase && len(words) > 1:
+			query.Phrases = append(query.Phrases, strings.Join(words, " "))
+		default:
+			// operators inside a word like c++ or e-mail split it into several words
+			query.Terms = append(query.Terms, words...)
+		}
+	}
+
+	return query
+}
+
+// a query made only of exclusions can not match anything
+func (q *Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// build the query for mysql MATCH ... AGAINST in boolean mode
+func (q *Query) BooleanMode() string {
+	parts := []string{}
+	for _, term := range q.Terms {
+		parts = append(parts, "+"+term+"*")
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `+"`+phrase+`"`)
+	}
+	for _, excluded := range q.Excluded {
+		if strings.Contains(excluded, " ") {
+			parts = append(parts, `-"`+excluded+`"`)
+		} else {
+			parts = append(parts, "-"+excluded)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// build the query for postgres to_tsquery, the words only hold letters and digits
+// so they need no quoting
+func (q *Query) TSQuery() string {
+	parts := []string{}
+	for _, term := range q.Terms {
+		parts = append(parts, term+":*")
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, "("+strings.ReplaceAll(phrase, " ", " <-> ")+")")
+	}
+	for _, excluded := range q.Excluded {
+		parts = append(parts, "!("+strings.ReplaceAll(excluded, " ", " <-> ")+")")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// cut the part of the text around the first match of the query and wrap every
+// match in it with <mark>, the rest of the snippet is html escaped
+func (q *Query) Highlight(text string, size int) string {
+	pattern := q.highlightPattern()
+
+	matches := [][]int{}
+	if pattern != nil {
+		for _, match := range pattern.FindAllStringIndex(text, -1) {
+			// the match has to start at the beginning of a word
+			prev, _ := utf8.DecodeLastRuneInString(text[:match[0]])
+			if match[0] > 0 && isWordRune(prev) {
+				continue
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	start := 0
+	if len(matches) > 0 {
+		start = matches[0][0] - size/3
+	}
+	start, end := snippetBounds(text, start, size)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+
+	pos := start
+	for _, match := range matches {
+		if match[0] < pos || match[1] > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:match[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[match[0]:match[1]]))
+		b.WriteString("</mark>")
+		pos = match[1]
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+
+	if end < len(text) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+func (q *Query) highlightPattern() *regexp.Regexp {
+	alternatives := []string{}
+	for _, phrase := range q.Phrases {
+		words := strings.Split(phrase, " ")
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		alternatives = append(alternatives, strings.Join(words, `[^\p{L}\p{N}]+`))
+	}
+	for _, term := range q.Terms {
+		// terms match as a prefix like the * in boolean mode
+		alternatives = append(alternatives, regexp.QuoteMeta(term)+`[\p{L}\p{N}]*`)
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+// move the snippet window of size bytes to whitespace so no word or character is cut
+func snippetBounds(text string, start int, size int) (int, int) {
+	if start+size > len(text) {
+		start = len(text) - size
+	}
+	if start <= 0 {
+		start = 0
+	} else if i := strings.IndexFunc(text[start:], unicode.IsSpace); i >= 0 && i < size/2 {
+		start += i + 1
+	} else {
+		for start < len(text) && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+
+	end := start + size
+	if end >= len(text) {
+		return start, len(text)
+	}
+	if i := strings.LastIndexFunc(text[start:end], unicode.IsSpace); i > size/2 {
+		end = start + i
+	} else {
+		for end > start && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	return start, end
+}
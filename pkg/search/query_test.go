@@ -0,0 +1,144 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want *Query
+	}{
+		{
+			name: "empty",
+			text: "  ",
+			want: &Query{},
+		},
+		{
+			name: "terms are lowercased",
+			text: "Go Generics",
+			want: &Query{Terms: []string{"go", "generics"}},
+		},
+		{
+			name: "phrase",
+			text: `"Error  Handling" go`,
+			want: &Query{Terms: []string{"go"}, Phrases: []string{"error handling"}},
+		},
+		{
+			name: "quoted single word is a term",
+			text: `"golang"`,
+			want: &Query{Terms: []string{"golang"}},
+		},
+		{
+			name: "unterminated quote takes the rest",
+			text: `go "error handling`,
+			want: &Query{Terms: []string{"go"}, Phrases: []string{"error handling"}},
+		},
+		{
+			name: "exclusions",
+			text: `go -java -"null pointer"`,
+			want: &Query{Terms: []string{"go"}, Excluded: []string{"java", "null pointer"}},
+		},
+		{
+			name: "operators inside a word split it",
+			text: "c++ e-mail",
+			want: &Query{Terms: []string{"c", "e", "mail"}},
+		},
+		{
+			name: "only exclusions",
+			text: "-java",
+			want: &Query{Excluded: []string{"java"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQuery(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryIsEmpty(t *testing.T) {
+	if !ParseQuery("-java").IsEmpty() {
+		t.Error("a query of only exclusions is not empty")
+	}
+	if ParseQuery("go -java").IsEmpty() {
+		t.Error("a query with a term is empty")
+	}
+}
+
+func TestQueryDatabaseSyntax(t *testing.T) {
+	query := ParseQuery(`go "error handling" -java -"null pointer"`)
+
+	if got, want := query.BooleanMode(), `+go* +"error handling" -java -"null pointer"`; got != want {
+		t.Errorf("BooleanMode() = %q, want %q", got, want)
+	}
+	if got, want := query.TSQuery(), "go:* & (error <-> handling) & !(java) & !(null <-> pointer)"; got != want {
+		t.Errorf("TSQuery() = %q, want %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		size  int
+		want  string
+	}{
+		{
+			name:  "terms match as a prefix at the start of a word",
+			query: "go",
+			text:  "Go and gophers, not ago",
+			size:  100,
+			want:  "<mark>Go</mark> and <mark>gophers</mark>, not ago",
+		},
+		{
+			name:  "phrase across punctuation",
+			query: `"error handling"`,
+			text:  "explicit error, handling here",
+			size:  100,
+			want:  "explicit <mark>error, handling</mark> here",
+		},
+		{
+			name:  "html is escaped",
+			query: "go",
+			text:  "<b>go</b>",
+			size:  100,
+			want:  "&lt;b&gt;<mark>go</mark>&lt;/b&gt;",
+		},
+		{
+			name:  "snippet around the first match",
+			query: "search",
+			text:  "lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt searching ut labore et dolore magna aliqua",
+			size:  40,
+			want:  "...incididunt <mark>searching</mark> ut labore et...",
+		},
+		{
+			name:  "no match shows the start",
+			query: "rust",
+			text:  "one two three four five six seven eight",
+			size:  20,
+			want:  "one two three four...",
+		},
+		{
+			name:  "only exclusions",
+			query: "-go",
+			text:  "go <tag>",
+			size:  100,
+			want:  "go &lt;tag&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQuery(tt.query).Highlight(tt.text, tt.size); got != tt.want {
+				t.Errorf("Highlight(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
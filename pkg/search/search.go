@@ -0,0 +1,31 @@
+package search
+
+import "context"
+
+const (
+	BACKEND_DATABASE = "database"
+	BACKEND_LOCAL    = "local"
+)
+
+// Document is the searchable content of an article
+type Document struct {
+	ID    int
+	Title string
+	Body  string
+}
+
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// SearchIndex finds the articles matching a query, ordered by relevance
+type SearchIndex interface {
+	// add the document or replace it when it is already indexed
+	Index(ctx context.Context, doc *Document) error
+	Delete(ctx context.Context, id int) error
+	// return at most limit hits, the most relevant first
+	Search(ctx context.Context, query *Query, limit int) ([]*Hit, error)
+	// replace the whole index with the documents
+	Rebuild(ctx context.Context, docs []*Document) error
+}
@@ -0,0 +1,88 @@
+package search
+
+import "strings"
+
+var (
+	indonesianParticles  = []string{"lah", "kah", "tah", "pun"}
+	indonesianPossessive = []string{"nya", "ku", "mu"}
+	indonesianSuffixes   = []string{"kan", "an", "i"}
+)
+
+// minimum length of a stem, affixes are only removed when enough of the word is left
+const indonesianMinStem = 4
+
+// reduce an indonesian word to its stem by removing the affixes in the order of
+// the nazief and adriani algorithm, without a dictionary the prefix recoding is
+// a best guess but the same word always gets the same stem
+func stemIndonesian(word string) string {
+	if len(word) <= indonesianMinStem {
+		return word
+	}
+
+	word = removeIndonesianSuffix(word, indonesianParticles)
+	word = removeIndonesianSuffix(word, indonesianPossessive)
+
+	stem, _ := removeIndonesianPrefix(word)
+	stem = removeIndonesianSuffix(stem, indonesianSuffixes)
+
+	if next, ok := removeIndonesianPrefix(stem); ok {
+		stem = next
+	}
+	return stem
+}
+
+func removeIndonesianSuffix(word string, suffixes []string) string {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= indonesianMinStem {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
+
+func removeIndonesianPrefix(word string) (string, bool) {
+	stem := word
+	switch {
+	case hasIndonesianPrefix(word, "meny"), hasIndonesianPrefix(word, "peny"):
+		// menyapu -> sapu
+		stem = "s" + word[4:]
+	case hasIndonesianPrefix(word, "meng"), hasIndonesianPrefix(word, "peng"):
+		stem = word[4:]
+	case hasIndonesianPrefix(word, "mem"), hasIndonesianPrefix(word, "pem"):
+		switch {
+		case isIndonesianVowel(word[3]):
+			// memakan -> makan
+			stem = word[2:]
+		case word[3] == 'r':
+			// pemrograman -> programan
+			stem = "p" + word[3:]
+		default:
+			stem = word[3:]
+		}
+	case hasIndonesianPrefix(word, "men"), hasIndonesianPrefix(word, "pen"):
+		if isIndonesianVowel(word[3]) {
+			// menulis -> tulis
+			stem = "t" + word[3:]
+		} else {
+			stem = word[3:]
+		}
+	case hasIndonesianPrefix(word, "ber"), hasIndonesianPrefix(word, "ter"), hasIndonesianPrefix(word, "per"):
+		stem = word[3:]
+	case hasIndonesianPrefix(word, "di"), hasIndonesianPrefix(word, "ke"), hasIndonesianPrefix(word, "se"),
+		hasIndonesianPrefix(word, "me"), hasIndonesianPrefix(word, "be"), hasIndonesianPrefix(word, "pe"):
+		stem = word[2:]
+	}
+
+	if stem == word || len(stem) < indonesianMinStem {
+		return word, false
+	}
+	return stem, true
+}
+
+func hasIndonesianPrefix(word string, prefix string) bool {
+	return strings.HasPrefix(word, prefix) && len(word) > len(prefix)
+}
+
+func isIndonesianVowel(c byte) bool {
+	return c == 'a' || c == 'e' || c == 'i' || c == 'o' || c == 'u'
+}
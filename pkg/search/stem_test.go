@@ -0,0 +1,77 @@
+package search
+
+import "testing"
+
+func TestStemEnglish(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "caresses", want: "caress"},
+		{word: "ponies", want: "poni"},
+		{word: "running", want: "run"},
+		{word: "hopping", want: "hop"},
+		{word: "agreed", want: "agre"},
+		{word: "happy", want: "happi"},
+		{word: "relational", want: "relat"},
+		{word: "connections", want: "connect"},
+		{word: "generalization", want: "gener"},
+		{word: "go", want: "go"},
+		{word: "café", want: "café"},
+		{word: "c++", want: "c++"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := stemEnglish(tt.word); got != tt.want {
+				t.Errorf("stemEnglish(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStemIndonesian(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "menyapu", want: "sapu"},
+		{word: "memakan", want: "makan"},
+		{word: "menulis", want: "tulis"},
+		{word: "pemrograman", want: "program"},
+		{word: "bermain", want: "main"},
+		{word: "dimakan", want: "makan"},
+		{word: "makanannya", want: "makan"},
+		{word: "bukunya", want: "buku"},
+		{word: "kebersihan", want: "bersih"},
+		{word: "mendengarkan", want: "dengar"},
+		{word: "rumah", want: "rumah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := stemIndonesian(tt.word); got != tt.want {
+				t.Errorf("stemIndonesian(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "the cat is on the table", want: LANGUAGE_ENGLISH},
+		{text: "saya sedang membaca buku yang ada di meja", want: LANGUAGE_INDONESIAN},
+		{text: "golang", want: LANGUAGE_ENGLISH},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := detectLanguage(words(tt.text)); got != tt.want {
+				t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
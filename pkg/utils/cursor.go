@@ -0,0 +1,19 @@
+package utils
+
+import "errors"
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encode the value into an opaque cursor signed with the secret
+func EncodeCursor(value interface{}, secret string) (string, error) {
+	return EncodeSigned(value, secret)
+}
+
+// decode a cursor made by EncodeCursor, cursors with a wrong signature are rejected
+func DecodeCursor(cursor string, secret string, value interface{}) error {
+	err := DecodeSigned(cursor, secret, value)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
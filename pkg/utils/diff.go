@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	DIFF_EQUAL  = "equal"
+	DIFF_INSERT = "insert"
+	DIFF_DELETE = "delete"
+)
+
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+// the most inserted and deleted lines a diff can have, the memory of the diff
+// grows with the square of the changes so very different texts are refused
+const MAX_DIFF_CHANGES = 1000
+
+var ErrDiffTooLarge = errors.New("the texts are too different to diff")
+
+// compare two texts line by line using the myers diff algorithm, it fails with
+// ErrDiffTooLarge when the texts need more than MAX_DIFF_CHANGES changes
+func LineDiff(from string, to string) ([]DiffLine, error) {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+
+	v := make([]int, 2*max+3)
+	// only the diagonals -d-1 to d+1 can be read back at step d so the trace
+	// keeps those instead of the whole v
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		if d > MAX_DIFF_CHANGES {
+			return nil, ErrDiffTooLarge
+		}
+
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b), nil
+			}
+		}
+	}
+
+	return []DiffLine{}, nil
+}
+
+func backtrackDiff(trace [][]int, a []string, b []string) []DiffLine {
+	x, y := len(a), len(b)
+	res := []DiffLine{}
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		// the snapshot of step d starts at the diagonal -d-1
+		offset := d + 1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			res = append(res, DiffLine{Op: DIFF_EQUAL, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				res = append(res, DiffLine{Op: DIFF_INSERT, Text: b[y-1]})
+			} else {
+				res = append(res, DiffLine{Op: DIFF_DELETE, Text: a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	// the lines were collected from the end
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// rebuild both texts from the ops, equal and deleted lines make the old text and
+// equal and inserted lines the new one
+func replayDiff(lines []DiffLine) (string, string) {
+	from, to := []string{}, []string{}
+	for _, line := range lines {
+		switch line.Op {
+		case DIFF_EQUAL:
+			from = append(from, line.Text)
+			to = append(to, line.Text)
+		case DIFF_DELETE:
+			from = append(from, line.Text)
+		case DIFF_INSERT:
+			to = append(to, line.Text)
+		}
+	}
+	return strings.Join(from, "\n"), strings.Join(to, "\n")
+}
+
+func countOps(lines []DiffLine) map[string]int {
+	res := map[string]int{}
+	for _, line := range lines {
+		res[line.Op]++
+	}
+	return res
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		ops  map[string]int
+	}{
+		{
+			name: "both empty",
+			ops:  map[string]int{},
+		},
+		{
+			name: "empty to text",
+			to:   "a\nb",
+			ops:  map[string]int{DIFF_INSERT: 2},
+		},
+		{
+			name: "text to empty",
+			from: "a\nb",
+			ops:  map[string]int{DIFF_DELETE: 2},
+		},
+		{
+			name: "identical",
+			from: "a\nb\nc",
+			to:   "a\nb\nc",
+			ops:  map[string]int{DIFF_EQUAL: 3},
+		},
+		{
+			name: "crlf is the same as lf",
+			from: "a\r\nb\r\nc",
+			to:   "a\nb\nc",
+			ops:  map[string]int{DIFF_EQUAL: 3},
+		},
+		{
+			name: "crlf with a change",
+			from: "a\r\nb\r\n",
+			to:   "a\r\nc\r\n",
+			ops:  map[string]int{DIFF_EQUAL: 2, DIFF_DELETE: 1, DIFF_INSERT: 1},
+		},
+		{
+			name: "interleaved inserts and deletes",
+			from: "a\nb\nc\nd\ne",
+			to:   "a\nx\nc\ny\ne\nz",
+			ops:  map[string]int{DIFF_EQUAL: 3, DIFF_DELETE: 2, DIFF_INSERT: 3},
+		},
+		{
+			name: "moved line",
+			from: "a\nb\nc",
+			to:   "c\na\nb",
+			ops:  map[string]int{DIFF_EQUAL: 2, DIFF_DELETE: 1, DIFF_INSERT: 1},
+		},
+		{
+			name: "trailing newline added",
+			from: "a",
+			to:   "a\n",
+			ops:  map[string]int{DIFF_EQUAL: 1, DIFF_INSERT: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := LineDiff(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			from, to := replayDiff(lines)
+			if want := strings.ReplaceAll(tt.from, "\r\n", "\n"); from != want {
+				t.Errorf("replayed old text = %q, want %q", from, want)
+			}
+			if want := strings.ReplaceAll(tt.to, "\r\n", "\n"); to != want {
+				t.Errorf("replayed new text = %q, want %q", to, want)
+			}
+
+			ops := countOps(lines)
+			for _, op := range []string{DIFF_EQUAL, DIFF_INSERT, DIFF_DELETE} {
+				if ops[op] != tt.ops[op] {
+					t.Errorf("%s lines = %d, want %d (diff %+v)", op, ops[op], tt.ops[op], lines)
+				}
+			}
+		})
+	}
+}
+
+func lcsLength(a []string, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] > table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+	return table[0][0]
+}
+
+// every text of up to four lines made of a, b and c
+func smallTexts() []string {
+	texts := []string{""}
+	prev := []string{""}
+	for size := 1; size <= 4; size++ {
+		next := []string{}
+		for _, text := range prev {
+			for _, line := range []string{"a", "b", "c"} {
+				if text == "" && size == 1 {
+					next = append(next, line)
+				} else {
+					next = append(next, text+"\n"+line)
+				}
+			}
+		}
+		texts = append(texts, next...)
+		prev = next
+	}
+	return texts
+}
+
+// the diff of every pair of small texts rebuilds both of them and is a shortest one
+func TestLineDiffIsMinimal(t *testing.T) {
+	texts := smallTexts()
+	for _, from := range texts {
+		for _, to := range texts {
+			lines, err := LineDiff(from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotFrom, gotTo := replayDiff(lines)
+			if gotFrom != from || gotTo != to {
+				t.Fatalf("LineDiff(%q, %q) replays to %q, %q", from, to, gotFrom, gotTo)
+			}
+
+			a, b := splitLines(from), splitLines(to)
+			if equal := countOps(lines)[DIFF_EQUAL]; equal != lcsLength(a, b) {
+				t.Fatalf("LineDiff(%q, %q) keeps %d lines, the longest common subsequence has %d", from, to, equal, lcsLength(a, b))
+			}
+		}
+	}
+}
+
+func numberedLines(prefix string, count int) string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestLineDiffLimit(t *testing.T) {
+	// half the changes are deletes and half inserts
+	lines, err := LineDiff(numberedLines("a", MAX_DIFF_CHANGES/2), numberedLines("b", MAX_DIFF_CHANGES/2))
+	if err != nil {
+		t.Fatalf("diff with %d changes: %v", MAX_DIFF_CHANGES, err)
+	}
+	if len(lines) != MAX_DIFF_CHANGES {
+		t.Errorf("diff has %d lines, want %d", len(lines), MAX_DIFF_CHANGES)
+	}
+
+	_, err = LineDiff(numberedLines("a", MAX_DIFF_CHANGES), numberedLines("b", MAX_DIFF_CHANGES))
+	if !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("diff with %d changes: err = %v, want ErrDiffTooLarge", 2*MAX_DIFF_CHANGES, err)
+	}
+
+	// long texts with a few changes are fine
+	long := numberedLines("a", 20000)
+	lines, err = LineDiff(long, long+"\nb")
+	if err != nil {
+		t.Fatalf("long texts with one change: %v", err)
+	}
+	if ops := countOps(lines); ops[DIFF_EQUAL] != 20000 || ops[DIFF_INSERT] != 1 {
+		t.Errorf("long texts with one change: ops = %v", ops)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	encrypted, err := Encrypt("JBSWY3DPEHPK3PXP", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == "JBSWY3DPEHPK3PXP" {
+		t.Fatal("the value is not encrypted")
+	}
+
+	again, err := Encrypt("JBSWY3DPEHPK3PXP", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encrypted {
+		t.Error("the same value encrypts to the same text")
+	}
+
+	value, err := Decrypt(encrypted, "secret")
+	if err != nil || value != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Decrypt = %q, %v", value, err)
+	}
+
+	tests := []struct {
+		name      string
+		encrypted string
+		secret    string
+		err       error
+	}{
+		{name: "wrong secret", encrypted: encrypted, secret: "another", err: ErrDecrypt},
+		{name: "changed", encrypted: encrypted[:len(encrypted)-2] + "AA", secret: "secret", err: ErrDecrypt},
+		{name: "too short", encrypted: "AAAA", secret: "secret", err: ErrDecrypt},
+		{name: "not base64", encrypted: "!!!", secret: "secret", err: ErrDecrypt},
+		{name: "empty secret", encrypted: encrypted, secret: "", err: ErrEmptySecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.encrypted, tt.secret)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Decrypt err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	_, err = Encrypt("value", "")
+	if !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("Encrypt with an empty secret err = %v, want %v", err, ErrEmptySecret)
+	}
+}
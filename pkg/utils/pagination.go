@@ -9,10 +9,21 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	DEFAULT_PER_PAGE = 10
+	MAX_PER_PAGE     = 100
+)
+
 func GetPagination(c echo.Context) *common.PaginationRequest {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	perPage, _ := strconv.Atoi(c.QueryParam("perpage"))
-	return GetPaginationV2(page, perPage)
+
+	pagination := GetPaginationV2(page, perPage)
+	if c.QueryParams().Has("cursor") {
+		pagination.CursorMode = true
+		pagination.Cursor = c.QueryParam("cursor")
+	}
+	return pagination
 }
 
 func GetPaginationV2(page int, perPage int) *common.PaginationRequest {
@@ -21,7 +32,11 @@ func GetPaginationV2(page int, perPage int) *common.PaginationRequest {
 	}
 
 	if perPage == 0 || perPage < 1 {
-		perPage = 1
+		perPage = DEFAULT_PER_PAGE
+	}
+
+	if perPage > MAX_PER_PAGE {
+		perPage = MAX_PER_PAGE
 	}
 
 	var offset int
@@ -54,6 +69,7 @@ func CalculateMetaPagination(totalData int64, p *common.PaginationRequest) *comm
 	}
 
 	return &common.Pagination{
+		Total:       totalData,
 		PerPage:     p.PerPage,
 		CurrentPage: p.Page,
 		IsLoadMore:  isLoadMore,
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	// anyone could sign a token or read an encrypted value when the secret is empty
+	ErrEmptySecret = errors.New("empty secret")
+)
+
+// encode the value into an opaque token signed with the secret, the value can be
+// read by anyone but not changed without the secret
+func EncodeSigned(value interface{}, secret string) (string, error) {
+	if secret == "" {
+		return "", ErrEmptySecret
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + sign(encoded, secret), nil
+}
+
+// decode a token made by EncodeSigned, tokens with a wrong signature are rejected
+func DecodeSigned(token string, secret string, value interface{}) error {
+	if secret == "" {
+		return ErrEmptySecret
+	}
+
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(encoded, secret))) {
+		return ErrInvalidSignature
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func sign(encoded string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type signedValue struct {
+	UserID string `json:"user_id"`
+}
+
+func mustEncodeSigned(t *testing.T, value interface{}, secret string) string {
+	token, err := EncodeSigned(value, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestSigned(t *testing.T) {
+	token, err := EncodeSigned(&signedValue{UserID: "1"}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := &signedValue{}
+	err = DecodeSigned(token, "secret", value)
+	if err != nil || value.UserID != "1" {
+		t.Fatalf("DecodeSigned = %+v, %v", value, err)
+	}
+
+	err = DecodeSigned(token, "other", &signedValue{})
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("decoding with another secret = %v, want ErrInvalidSignature", err)
+	}
+
+	// another value under the signature of the first one
+	_, signature, _ := strings.Cut(token, ".")
+	other, _, _ := strings.Cut(mustEncodeSigned(t, &signedValue{UserID: "2"}, "secret"), ".")
+	err = DecodeSigned(other+"."+signature, "secret", &signedValue{})
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("decoding a changed value = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSignedRejectsEmptySecret(t *testing.T) {
+	_, err := EncodeSigned(&signedValue{UserID: "1"}, "")
+	if !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("EncodeSigned with an empty secret = %v, want ErrEmptySecret", err)
+	}
+
+	// a token signed with the empty key by someone else is not accepted either
+	encoded := "eyJ1c2VyX2lkIjoiMSJ9"
+	err = DecodeSigned(encoded+"."+sign(encoded, ""), "", &signedValue{})
+	if !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("DecodeSigned with an empty secret = %v, want ErrEmptySecret", err)
+	}
+}
@@ -3,12 +3,12 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"net/http"
 	"reflect"
 	"strings"
 
+	"github.com/haikalvidya/go-article/pkg/apperror"
+
 	"github.com/go-playground/validator/v10"
-	"github.com/labstack/echo/v4"
 )
 
 type CustomValidator struct {
@@ -52,12 +52,12 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 			for _, fe := range ve {
 				errorRes[fe.Field()] = msgForTag(fe, fe.Field())
 			}
-			return echo.NewHTTPError(http.StatusBadRequest, errorRes)
+			return apperror.ErrValidation.WithDetails(errorRes)
 		}
 	}
 	return nil
 }
 
 func GetErrorValidation(err error) interface{} {
-	return err.(*echo.HTTPError).Message
+	return apperror.From(err).Details
 }